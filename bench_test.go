@@ -0,0 +1,125 @@
+package voynich
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// benchSizes are the input sizes, in bytes of text, the benchmarks run at.
+var benchSizes = []int{1 << 10, 64 << 10, 1 << 20}
+
+// benchText returns n bytes of English-like text: words drawn with a fixed
+// seed from a small vocabulary, so the decoder sees realistic repetition
+// without the input being one phrase repeated verbatim.
+func benchText(n int) string {
+	words := strings.Fields("the of and to in a is that for it as was with be by on not he " +
+		"this are or his from at which but have an they you were her she there one all we")
+	source := rand.New(rand.NewSource(1))
+
+	var text strings.Builder
+	text.Grow(n + 16)
+	for text.Len() < n {
+		text.WriteString(words[source.Intn(len(words))])
+		text.WriteByte(' ')
+	}
+	return text.String()[:n]
+}
+
+// sizeName labels a sub-benchmark by its input size, such as "64KB".
+func sizeName(size int) string {
+	if size >= 1<<20 {
+		return fmt.Sprintf("%dMB", size>>20)
+	}
+	return fmt.Sprintf("%dKB", size>>10)
+}
+
+func BenchmarkDecodeLZ77(b *testing.B) {
+	for _, size := range benchSizes {
+		bitStream, err := EncodeLZ77(benchText(size), 10, 4)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(sizeName(size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(size))
+			for i := 0; i < b.N; i++ {
+				if _, err := DecodeLZ77(bitStream, 10, 4); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkShannonEntropy(b *testing.B) {
+	for _, size := range benchSizes {
+		text := benchText(size)
+		b.Run(sizeName(size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(size))
+			for i := 0; i < b.N; i++ {
+				ShannonEntropy(text)
+			}
+		})
+	}
+}
+
+func BenchmarkGenerateBitStream(b *testing.B) {
+	for _, size := range benchSizes {
+		text := benchText(size)
+		b.Run(sizeName(size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(size))
+			for i := 0; i < b.N; i++ {
+				GenerateBitStream(text)
+			}
+		})
+	}
+}
+
+// BenchmarkCharCounts compares the serial and parallel histogram builds on
+// either side of parallelCountThreshold.
+func BenchmarkCharCounts(b *testing.B) {
+	for _, size := range []int{parallelCountThreshold / 4, parallelCountThreshold, 4 * parallelCountThreshold} {
+		text := benchText(size)
+		b.Run(sizeName(size)+"/serial", func(b *testing.B) {
+			b.SetBytes(int64(size))
+			for i := 0; i < b.N; i++ {
+				countRunes(text)
+			}
+		})
+		b.Run(sizeName(size)+"/parallel", func(b *testing.B) {
+			b.SetBytes(int64(size))
+			for i := 0; i < b.N; i++ {
+				countRunesParallel(text, runtime.NumCPU())
+			}
+		})
+	}
+}
+
+// BenchmarkWindowPush compares the decoder's ring buffer with the
+// append-and-reslice window it replaced, which copied the whole window
+// each time append ran out of capacity.
+func BenchmarkWindowPush(b *testing.B) {
+	const windowSize = 1 << 16
+	b.Run("ring", func(b *testing.B) {
+		b.ReportAllocs()
+		window := ringWindow{size: windowSize}
+		for i := 0; i < b.N; i++ {
+			window.push(rune(i))
+		}
+	})
+	b.Run("slice", func(b *testing.B) {
+		b.ReportAllocs()
+		var window []rune
+		for i := 0; i < b.N; i++ {
+			window = append(window, rune(i))
+			if len(window) > windowSize {
+				window = window[1:]
+			}
+		}
+	})
+}
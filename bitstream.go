@@ -0,0 +1,371 @@
+package voynich
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"unicode"
+)
+
+// BitOrder selects how a fixed-width field is interpreted as an integer.
+type BitOrder int
+
+const (
+	// MSBFirst reads the first bit of a field as its most significant bit.
+	MSBFirst BitOrder = iota
+	// LSBFirst reads the first bit of a field as its least significant bit.
+	LSBFirst
+)
+
+// String returns the short name used in reports.
+func (o BitOrder) String() string {
+	switch o {
+	case MSBFirst:
+		return "MSB"
+	case LSBFirst:
+		return "LSB"
+	default:
+		return "unknown"
+	}
+}
+
+// GenerateBitStream creates a demonstration bitstream from text using simple encoding
+func GenerateBitStream(text string) string {
+	var bitStream strings.Builder
+	for _, char := range text {
+		// Simple 8-bit ASCII encoding
+		for i := 7; i >= 0; i-- {
+			if (char>>uint(i))&1 == 1 {
+				bitStream.WriteString("1")
+			} else {
+				bitStream.WriteString("0")
+			}
+		}
+	}
+	return bitStream.String()
+}
+
+// GenerateBitStreamAlphabet encodes text over a custom glyph alphabet, such
+// as EVA letters, emitting each rune's index in the alphabet as an
+// MSB-first field of bitsPerSymbol bits. A rune missing from the alphabet,
+// or an alphabet too large for bitsPerSymbol, is an error.
+// DecodeBitStreamAlphabet reverses the encoding.
+func GenerateBitStreamAlphabet(text string, alphabet []rune, bitsPerSymbol int) (string, error) {
+	index, err := alphabetIndex(alphabet, bitsPerSymbol)
+	if err != nil {
+		return "", err
+	}
+
+	var bitStream strings.Builder
+	for position, char := range text {
+		code, ok := index[char]
+		if !ok {
+			return "", fmt.Errorf("character %q at position %d is not in the alphabet", char, position)
+		}
+		writeBits(&bitStream, code, bitsPerSymbol)
+	}
+	return bitStream.String(), nil
+}
+
+// DecodeBitStreamAlphabet decodes a bitstream produced by
+// GenerateBitStreamAlphabet with the same alphabet and symbol width. A code
+// past the end of the alphabet, or trailing bits too short for a symbol,
+// is an error.
+func DecodeBitStreamAlphabet(bitStream string, alphabet []rune, bitsPerSymbol int) (string, error) {
+	if _, err := alphabetIndex(alphabet, bitsPerSymbol); err != nil {
+		return "", err
+	}
+	if len(bitStream)%bitsPerSymbol != 0 {
+		return "", fmt.Errorf("bitstream length %d is not a multiple of %d bits", len(bitStream), bitsPerSymbol)
+	}
+
+	var output strings.Builder
+	for position := 0; position < len(bitStream); position += bitsPerSymbol {
+		code := readBits(bitStream[position:position+bitsPerSymbol], MSBFirst)
+		if code >= len(alphabet) {
+			return output.String(), fmt.Errorf("code %d at position %d is outside the %d-symbol alphabet",
+				code, position, len(alphabet))
+		}
+		output.WriteRune(alphabet[code])
+	}
+	return output.String(), nil
+}
+
+// GenerateBitStreamCodes encodes text with a variable-length prefix code
+// mapping each rune to its '0'/'1' code, such as one returned by
+// BuildHuffman. A rune with no code, or a code containing other characters,
+// is an error. The result decodes with DecodeHuffman and InvertCodes(codes).
+func GenerateBitStreamCodes(text string, codes map[rune]string) (string, error) {
+	var bitStream strings.Builder
+	for position, char := range text {
+		code, ok := codes[char]
+		if !ok || code == "" {
+			return "", fmt.Errorf("character %q at position %d has no code", char, position)
+		}
+		if strings.Trim(code, "01") != "" {
+			return "", fmt.Errorf("code %q for character %q is not a bitstream", code, char)
+		}
+		bitStream.WriteString(code)
+	}
+	return bitStream.String(), nil
+}
+
+// alphabetIndex validates a glyph alphabet for the given symbol width and
+// maps each rune to its code.
+func alphabetIndex(alphabet []rune, bitsPerSymbol int) (map[rune]int, error) {
+	if bitsPerSymbol < 1 || bitsPerSymbol > maxFieldBits {
+		return nil, fmt.Errorf("bitsPerSymbol must be between 1 and %d, got %d", maxFieldBits, bitsPerSymbol)
+	}
+	if len(alphabet) == 0 {
+		return nil, errors.New("alphabet is empty")
+	}
+	if len(alphabet) > 1<<bitsPerSymbol {
+		return nil, fmt.Errorf("alphabet of %d symbols does not fit in %d bits", len(alphabet), bitsPerSymbol)
+	}
+
+	index := make(map[rune]int, len(alphabet))
+	for code, char := range alphabet {
+		if _, ok := index[char]; ok {
+			return nil, fmt.Errorf("alphabet contains %q more than once", char)
+		}
+		index[char] = code
+	}
+	return index, nil
+}
+
+// ParseBitStream reads a bitstream written as text of '0' and '1'
+// characters, as researchers commonly share them. Whitespace, including line
+// breaks, is ignored; any other character is an error reporting its byte
+// position in the input.
+func ParseBitStream(text string) (string, error) {
+	var bitStream strings.Builder
+	for position, char := range text {
+		switch {
+		case char == '0' || char == '1':
+			bitStream.WriteRune(char)
+		case unicode.IsSpace(char):
+			// Skip layout whitespace
+		default:
+			return "", fmt.Errorf("invalid bitstream character %q at position %d", char, position)
+		}
+	}
+	return bitStream.String(), nil
+}
+
+// PackBits packs a bitstream of '0'/'1' characters into bytes, eight bits
+// per byte with the first bit in the most significant position. The final
+// byte is padded with zero bits; keep len(bitStream) to undo the padding
+// with UnpackBits.
+func PackBits(bitStream string) ([]byte, error) {
+	packed := make([]byte, (len(bitStream)+7)/8)
+	for i := 0; i < len(bitStream); i++ {
+		switch bitStream[i] {
+		case '1':
+			packed[i/8] |= 1 << (7 - uint(i%8))
+		case '0':
+		default:
+			return nil, fmt.Errorf("invalid bitstream character %q at position %d", bitStream[i], i)
+		}
+	}
+	return packed, nil
+}
+
+// UnpackBits expands bytes produced by PackBits back into a bitstream of
+// bitLen '0'/'1' characters, dropping the padding in the final byte. A
+// bitLen larger than the data holds is limited to len(data)*8, and a
+// negative bitLen yields an empty bitstream.
+func UnpackBits(data []byte, bitLen int) string {
+	if bitLen > len(data)*8 {
+		bitLen = len(data) * 8
+	}
+	if bitLen < 0 {
+		bitLen = 0
+	}
+
+	var bitStream strings.Builder
+	bitStream.Grow(bitLen)
+	for i := 0; i < bitLen; i++ {
+		if data[i/8]&(1<<(7-uint(i%8))) != 0 {
+			bitStream.WriteByte('1')
+		} else {
+			bitStream.WriteByte('0')
+		}
+	}
+	return bitStream.String()
+}
+
+// writeBits appends value to the builder as an MSB-first field of width bits.
+func writeBits(bitStream *strings.Builder, value, width int) {
+	for i := width - 1; i >= 0; i-- {
+		if (value>>uint(i))&1 == 1 {
+			bitStream.WriteByte('1')
+		} else {
+			bitStream.WriteByte('0')
+		}
+	}
+}
+
+// readBits interprets a field of '0'/'1' characters as an unsigned integer
+// using the given bit order.
+func readBits(field string, order BitOrder) int {
+	value := 0
+	for i := 0; i < len(field); i++ {
+		if field[i] != '1' {
+			continue
+		}
+		if order == LSBFirst {
+			value += 1 << i
+		} else {
+			value += 1 << (len(field) - 1 - i)
+		}
+	}
+	return value
+}
+
+// DecodeRaw reads the bitstream as plain fixed-width characters with no
+// compression: each MSB-first field of bits bits is one Unicode code point,
+// so 8 bits reads it as bytes of Latin-1 text. It is the null-hypothesis
+// decode to compare LZ77 decodes against. A value that is not a valid code
+// point, a surrogate from 16 bits or anything above U+10FFFF from 21 bits,
+// is written as U+FFFD. Trailing bits too few for a whole symbol are
+// ignored, and a width outside 1 to 30 bits yields "".
+func DecodeRaw(bitStream string, bits int) string {
+	if bits < 1 || bits > maxFieldBits {
+		return ""
+	}
+
+	var output strings.Builder
+	for position := 0; position+bits <= len(bitStream); position += bits {
+		output.WriteRune(rune(readBits(bitStream[position:position+bits], MSBFirst)))
+	}
+	return output.String()
+}
+
+// InferLiteralWidth guesses the symbol width, 4 to 8 bits, of a stream of
+// uncompressed fixed-width symbols by reading it with DecodeRaw at each
+// width and picking the most structured result. Raw entropies are not
+// comparable across widths: wider symbols carry more entropy, and a short
+// stream read at a wide width has too few symbols to show it. Each width is
+// therefore scored by its entropy relative to the same stream with its
+// bits shuffled, which keeps the symbol count and the balance of 0s and 1s
+// but destroys any structure. At the true width the symbols keep the skew
+// of the text they encode and score well below 1; at a wrong width they
+// straddle symbol boundaries and score close to it. The shuffles use a
+// fixed seed, so the result is reproducible.
+//
+// A stream of 4-bit symbols also reads as structured 8-bit pairs and may be
+// reported as 8. Trailing bits that do not fill a symbol are ignored, and
+// ties go to the smaller width. A stream too short for two symbols of
+// every candidate width, or made of a single repeated bit, returns 0, the
+// LiteralBits value that selects the decoder's default width.
+func InferLiteralWidth(bitStream string) int {
+	const minWidth, maxWidth = 4, 8
+	const shuffles = 4
+	if len(bitStream) < 2*maxWidth {
+		return 0
+	}
+
+	source := rand.New(rand.NewSource(1))
+	baselines := make([]string, shuffles)
+	for i := range baselines {
+		bits := []byte(bitStream)
+		source.Shuffle(len(bits), func(a, b int) { bits[a], bits[b] = bits[b], bits[a] })
+		baselines[i] = string(bits)
+	}
+
+	best, bestScore := 0, math.Inf(1)
+	for width := minWidth; width <= maxWidth; width++ {
+		var baseline float64
+		for _, shuffled := range baselines {
+			baseline += ShannonEntropy(DecodeRaw(shuffled, width)) / shuffles
+		}
+		if baseline == 0 {
+			continue // A constant stream has no structure to find
+		}
+		if score := ShannonEntropy(DecodeRaw(bitStream, width)) / baseline; score < bestScore {
+			best, bestScore = width, score
+		}
+	}
+	return best
+}
+
+// StripBits splits off the first n bits of a bitstream, such as a
+// fixed-size header in front of the compressed data, and returns the
+// header and the rest separately. An n larger than the stream makes the
+// whole stream the header, and a negative n strips nothing.
+// DecoderConfig.HeaderBits skips a header while decoding.
+func StripBits(bitStream string, n int) (header, rest string) {
+	if n < 0 {
+		n = 0
+	}
+	if n > len(bitStream) {
+		n = len(bitStream)
+	}
+	return bitStream[:n], bitStream[n:]
+}
+
+// ReverseBits returns the bitstream read back to front, bit by bit rather
+// than byte by byte, for encodings written from the end. Reversing twice
+// gives back the original stream.
+func ReverseBits(bitStream string) string {
+	reversed := make([]byte, len(bitStream))
+	for i := 0; i < len(bitStream); i++ {
+		reversed[len(bitStream)-1-i] = bitStream[i]
+	}
+	return string(reversed)
+}
+
+// BitEntropy computes the entropy in bits/bit of the '0'/'1' distribution
+// of a bitstream. Compressed or random data is close to 1; much lower
+// values suggest the stream is not compressed. Other characters are ignored.
+func BitEntropy(bitStream string) float64 {
+	counts := make(map[byte]int, 2)
+	for i := 0; i < len(bitStream); i++ {
+		if bitStream[i] == '0' || bitStream[i] == '1' {
+			counts[bitStream[i]]++
+		}
+	}
+	return entropyOfCounts(counts, counts['0']+counts['1'])
+}
+
+// BitRunLengths returns a histogram of the lengths of maximal runs of
+// identical consecutive bits: "0001101" has runs 3, 2, 1 and 1.
+func BitRunLengths(bitStream string) map[int]int {
+	histogram := make(map[int]int)
+	runLength := 0
+	for i := 0; i < len(bitStream); i++ {
+		if i > 0 && bitStream[i] != bitStream[i-1] {
+			histogram[runLength]++
+			runLength = 0
+		}
+		runLength++
+	}
+	if runLength > 0 {
+		histogram[runLength]++
+	}
+	return histogram
+}
+
+// RandomBitStream returns n pseudo-random bits generated from the given
+// seed, so the same seed always yields the same stream. It provides
+// reproducible inputs for stress-testing the decoders and a random baseline
+// to compare decodes against.
+func RandomBitStream(n int, seed int64) string {
+	if n <= 0 {
+		return ""
+	}
+
+	source := rand.New(rand.NewSource(seed))
+	var bitStream strings.Builder
+	bitStream.Grow(n)
+	for i := 0; i < n; i++ {
+		if source.Intn(2) == 1 {
+			bitStream.WriteByte('1')
+		} else {
+			bitStream.WriteByte('0')
+		}
+	}
+	return bitStream.String()
+}
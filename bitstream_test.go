@@ -0,0 +1,271 @@
+package voynich
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestParseBitStream(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr string
+	}{
+		{name: "plain", text: "0101", want: "0101"},
+		{name: "spaces and tabs", text: "01 10\t11", want: "011011"},
+		{name: "line breaks", text: "0101\n1100\r\n0011\n", want: "010111000011"},
+		{name: "only whitespace", text: " \n\t", want: ""},
+		{name: "bad character", text: "01 1x0", wantErr: `invalid bitstream character 'x' at position 4`},
+		// Positions are byte offsets into the text, after multi-byte runes
+		{name: "after multi-byte rune", text: " 01é", wantErr: `invalid bitstream character 'é' at position 4`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBitStream(tt.text)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackBitsRoundTrip(t *testing.T) {
+	for _, bitStream := range []string{"", "1", "0000000", "10110010", "101100101", RandomBitStream(1001, 7)} {
+		packed, err := PackBits(bitStream)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := (len(bitStream) + 7) / 8; len(packed) != want {
+			t.Errorf("PackBits packed %d bits into %d bytes, want %d", len(bitStream), len(packed), want)
+		}
+		if got := UnpackBits(packed, len(bitStream)); got != bitStream {
+			t.Errorf("UnpackBits(PackBits(%q)) = %q", bitStream, got)
+		}
+	}
+
+	packed, _ := PackBits("101")
+	if packed[0] != 0xA0 {
+		t.Errorf("PackBits(\"101\") = %08b, want 10100000", packed[0])
+	}
+	if got := UnpackBits(packed, 100); got != "10100000" {
+		t.Errorf("UnpackBits past the data = %q, want the whole byte", got)
+	}
+	if _, err := PackBits("0120"); err == nil || err.Error() != `invalid bitstream character '2' at position 2` {
+		t.Errorf("error = %v, want the '2' rejected", err)
+	}
+}
+
+func TestBitStreamAlphabetRoundTrip(t *testing.T) {
+	// Fifteen EVA glyphs and the '.' word separator fill a 4-bit code
+	alphabet := []rune("acde.hiklmnopqry")
+	text := "qokeedy.daiin.chol.qokain.okeey"
+
+	bitStream, err := GenerateBitStreamAlphabet(text, alphabet, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bitStream) != 4*len(text) {
+		t.Errorf("encoded %d symbols in %d bits, want %d", len(text), len(bitStream), 4*len(text))
+	}
+	got, err := DecodeBitStreamAlphabet(bitStream, alphabet, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != text {
+		t.Errorf("round trip gave %q, want %q", got, text)
+	}
+
+	if _, err := GenerateBitStreamAlphabet("ab", append(alphabet, 'z'), 4); err == nil {
+		t.Error("17-symbol alphabet accepted at 4 bits")
+	}
+	if _, err := GenerateBitStreamAlphabet("acz", alphabet, 4); err == nil || !strings.Contains(err.Error(), "position 2") {
+		t.Errorf("error = %v, want 'z' at position 2 rejected", err)
+	}
+	if _, err := DecodeBitStreamAlphabet("1111", alphabet[:15], 4); err == nil {
+		t.Error("code 15 accepted for a 15-symbol alphabet")
+	}
+	if _, err := DecodeBitStreamAlphabet("00001", alphabet, 4); err == nil {
+		t.Error("trailing bit accepted")
+	}
+}
+
+func TestBitEntropyAndRunLengths(t *testing.T) {
+	zeros := strings.Repeat("0", 64)
+	alternating := strings.Repeat("01", 32)
+
+	if got := BitEntropy(zeros); got != 0 {
+		t.Errorf("BitEntropy(all zeros) = %v, want 0", got)
+	}
+	if got := BitEntropy(alternating); got != 1 {
+		t.Errorf("BitEntropy(alternating) = %v, want 1", got)
+	}
+	if got := BitEntropy("0 1\n"); got != 1 {
+		t.Errorf("BitEntropy ignoring whitespace = %v, want 1", got)
+	}
+
+	if got := BitRunLengths(zeros); !maps.Equal(got, map[int]int{64: 1}) {
+		t.Errorf("BitRunLengths(all zeros) = %v, want one run of 64", got)
+	}
+	if got := BitRunLengths(alternating); !maps.Equal(got, map[int]int{1: 64}) {
+		t.Errorf("BitRunLengths(alternating) = %v, want 64 runs of 1", got)
+	}
+	if got := BitRunLengths("0001101"); !maps.Equal(got, map[int]int{3: 1, 2: 1, 1: 2}) {
+		t.Errorf("BitRunLengths(\"0001101\") = %v", got)
+	}
+	if got := BitRunLengths(""); len(got) != 0 {
+		t.Errorf("BitRunLengths(\"\") = %v, want no runs", got)
+	}
+}
+
+func TestRandomBitStream(t *testing.T) {
+	a, b := RandomBitStream(4096, 42), RandomBitStream(4096, 42)
+	if a != b {
+		t.Error("the same seed gave different streams")
+	}
+	if RandomBitStream(4096, 43) == a {
+		t.Error("different seeds gave the same stream")
+	}
+	if len(a) != 4096 || strings.Trim(a, "01") != "" {
+		t.Errorf("got %d characters, want 4096 bits", len(a))
+	}
+	if got := BitEntropy(a); got < 0.99 {
+		t.Errorf("BitEntropy = %v, want close to 1", got)
+	}
+	// A prefix does not depend on the requested length
+	if RandomBitStream(100, 42) != a[:100] {
+		t.Error("a shorter stream from the same seed is not a prefix")
+	}
+	if got := RandomBitStream(-1, 42); got != "" {
+		t.Errorf("RandomBitStream(-1) = %q, want empty", got)
+	}
+}
+
+// TestDecodeRandomStreams is the property test RandomBitStream exists for:
+// the decoder never panics on arbitrary bits and never outputs more
+// symbols than literals and references can account for.
+func TestDecodeRandomStreams(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		bitStream := RandomBitStream(2000, seed)
+		result, _ := DecodeLZ77WithOptions(bitStream, 8, 4, DecodeOptions{})
+		if n := len([]rune(result.Output)); n != result.LiteralBytes+result.ReferenceBytes {
+			t.Errorf("seed %d: %d output symbols, want LiteralBytes + ReferenceBytes = %d",
+				seed, n, result.LiteralBytes+result.ReferenceBytes)
+		}
+		// Every command takes at least 9 bits and yields at most 15 symbols
+		if max := len(bitStream) / 9 * 15; result.LiteralBytes+result.ReferenceBytes > max {
+			t.Errorf("seed %d: %d output symbols from %d bits", seed, len(result.Output), len(bitStream))
+		}
+	}
+}
+
+func TestReverseBits(t *testing.T) {
+	if got := ReverseBits("110100"); got != "001011" {
+		t.Errorf("ReverseBits(\"110100\") = %q, want %q", got, "001011")
+	}
+	for _, bitStream := range []string{"", "1", "10", RandomBitStream(1001, 3)} {
+		if got := ReverseBits(ReverseBits(bitStream)); got != bitStream {
+			t.Errorf("reversing %q twice gave %q", bitStream, got)
+		}
+	}
+}
+
+func TestDecodeRaw(t *testing.T) {
+	tests := []struct {
+		name      string
+		bitStream string
+		bits      int
+		want      string
+	}{
+		{name: "bytes", bitStream: GenerateBitStream("Voynich"), bits: 8, want: "Voynich"},
+		{name: "latin-1", bitStream: "11101001", bits: 8, want: "é"},
+		{name: "trailing bits", bitStream: GenerateBitStream("ab") + "0110", bits: 8, want: "ab"},
+		{name: "7-bit ASCII", bitStream: "1000001" + "1000010", bits: 7, want: "AB"},
+		{name: "16-bit", bitStream: "0000" + "0011" + "1011" + "0001", bits: 16, want: "α"},
+		{name: "surrogate", bitStream: "1101100000000000", bits: 16, want: "\uFFFD"},
+		{name: "above U+10FFFF", bitStream: "111111111111111111111", bits: 21, want: "\uFFFD"},
+		{name: "too short", bitStream: "0101", bits: 8, want: ""},
+		{name: "width 0", bitStream: "0101", bits: 0, want: ""},
+		{name: "width 31", bitStream: strings.Repeat("0", 62), bits: 31, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DecodeRaw(tt.bitStream, tt.bits); got != tt.want {
+				t.Errorf("DecodeRaw = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInferLiteralWidth(t *testing.T) {
+	text := benchText(2 << 10)
+	fiveBit, err := GenerateBitStreamAlphabet(text, []rune(" abcdefghijklmnopqrstuvwxyz"), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		bitStream string
+		want      int
+	}{
+		{"5-bit codes", fiveBit, 5},
+		{"8-bit codes", GenerateBitStream(text), 8},
+		{"too short", "010110100101101", 0},
+		{"constant", strings.Repeat("1", 1000), 0},
+	}
+	for _, tt := range tests {
+		if got := InferLiteralWidth(tt.bitStream); got != tt.want {
+			t.Errorf("%s: InferLiteralWidth = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHeader(t *testing.T) {
+	const header = "1100101011110000"
+	body, err := EncodeLZ77(phaseText, 10, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bitStream := header + body
+
+	gotHeader, rest := StripBits(bitStream, 16)
+	if gotHeader != header || rest != body {
+		t.Errorf("StripBits split off %q, want %q", gotHeader, header)
+	}
+	decoded, err := DecodeLZ77(rest, 10, 4)
+	if err != nil || decoded != phaseText {
+		t.Errorf("decoding after StripBits gave %q, %v, want %q", decoded, err, phaseText)
+	}
+
+	cfg := DecoderConfig{OffsetBits: 10, LengthBits: 4, HeaderBits: 16}
+	result, err := DecodeWithConfig(bitStream, cfg)
+	if err != nil || result.Output != phaseText {
+		t.Errorf("decoding with HeaderBits gave %q, %v, want %q", result.Output, err, phaseText)
+	}
+
+	// Error positions count from the start of the whole stream
+	_, err = DecodeWithConfig(header+"0011000010"+"0", cfg)
+	if want := "incomplete literal at position 26"; err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %q", err, want)
+	}
+
+	if header, rest := StripBits("0101", 10); header != "0101" || rest != "" {
+		t.Errorf("StripBits past the end = %q, %q, want the whole stream as header", header, rest)
+	}
+	if header, rest := StripBits("0101", -1); header != "" || rest != "0101" {
+		t.Errorf("StripBits(-1) = %q, %q, want nothing stripped", header, rest)
+	}
+	if _, err := DecodeWithConfig("0101", cfg); err == nil {
+		t.Error("decoding a stream shorter than its header succeeded, want an error")
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/djabbat/voynich"
+)
+
+// runEncode compresses a text into an LZ77 bitstream, or writes its plain
+// 8-bit encoding with -raw, and prints the bitstream on one line.
+func runEncode(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("voynich encode", flag.ContinueOnError)
+	inputPath := flags.String("input", "", "read the text from `path` instead of standard input")
+	offsetBits := flags.Int("offset-bits", 10, "offset field `width` in bits")
+	lengthBits := flags.Int("length-bits", 4, "length field `width` in bits")
+	raw := flags.Bool("raw", false, "write the plain 8-bit encoding instead of compressing")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	text, err := readInput(*inputPath, stdin)
+	if err != nil {
+		return err
+	}
+	// Drop the line break that ends a file or an echo
+	text = strings.TrimSuffix(strings.TrimSuffix(text, "\n"), "\r")
+
+	var bitStream string
+	if *raw {
+		bitStream = voynich.GenerateBitStream(text)
+	} else if bitStream, err = voynich.EncodeLZ77(text, *offsetBits, *lengthBits); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, bitStream)
+	return err
+}
+
+// runDecode decompresses a bitstream and prints the decoded text. The
+// settings come from -config when it is given, and any field-width,
+// bit-order or header flag set explicitly overrides the config. On a decode error
+// the output decoded so far is still printed.
+func runDecode(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("voynich decode", flag.ContinueOnError)
+	bitStreamPath := flags.String("bitstream", "", "read the bitstream from `path` instead of standard input")
+	offsetBits := flags.Int("offset-bits", 10, "offset field `width` in bits")
+	lengthBits := flags.Int("length-bits", 4, "length field `width` in bits")
+	order := flags.String("order", "MSB", "field bit `order`: MSB or LSB")
+	headerBits := flags.Int("header-bits", 0, "skip a header of `n` bits before decoding")
+	configPath := flags.String("config", "", "load the decoder settings from the JSON file at `path`")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg := voynich.DecoderConfig{OffsetBits: *offsetBits, LengthBits: *lengthBits}
+	if *configPath != "" {
+		var err error
+		if cfg, err = readConfig(*configPath); err != nil {
+			return err
+		}
+	}
+
+	// Explicit flags take precedence over the config file
+	var orderErr error
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "offset-bits":
+			cfg.OffsetBits = *offsetBits
+		case "length-bits":
+			cfg.LengthBits = *lengthBits
+		case "order":
+			orderErr = cfg.BitOrder.UnmarshalText([]byte(*order))
+		case "header-bits":
+			cfg.HeaderBits = *headerBits
+		}
+	})
+	if orderErr != nil {
+		return orderErr
+	}
+
+	bitStream, err := readBitStream(*bitStreamPath, stdin)
+	if err != nil {
+		return err
+	}
+
+	result, decodeErr := voynich.DecodeWithConfig(bitStream, cfg)
+	if _, err := fmt.Fprintln(stdout, result.Output); err != nil {
+		return err
+	}
+	return decodeErr
+}
+
+// readConfig loads a DecoderConfig from a JSON file.
+func readConfig(path string) (voynich.DecoderConfig, error) {
+	var cfg voynich.DecoderConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("reading config: %w", err)
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// runAnalyze prints the character, word and language statistics of a text
+// followed by a bar chart of its character frequencies.
+func runAnalyze(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("voynich analyze", flag.ContinueOnError)
+	inputPath := flags.String("input", "", "read the text from `path` instead of standard input")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	text, err := readInput(*inputPath, stdin)
+	if err != nil {
+		return err
+	}
+
+	stats := []struct{ label, value string }{
+		{"Characters", fmt.Sprintf("%d", utf8.RuneCountInString(text))},
+		{"Alphabet size", fmt.Sprintf("%d", voynich.AlphabetSize(text))},
+		{"Shannon entropy", fmt.Sprintf("%.4f bits/character", voynich.ShannonEntropy(text))},
+		{"Perplexity", fmt.Sprintf("%.2f characters", voynich.Perplexity(text))},
+		{"Without whitespace", fmt.Sprintf("%.4f bits/character", voynich.EntropyIgnoringWhitespace(text))},
+		{"Conditional entropy", fmt.Sprintf("%.4f bits/character", voynich.ConditionalEntropy(text))},
+		{"Redundancy", fmt.Sprintf("%.4f", voynich.Redundancy(text))},
+		{"Index of coincidence", fmt.Sprintf("%.4f", voynich.IndexOfCoincidence(text))},
+		{"Word entropy", fmt.Sprintf("%.4f bits/word", voynich.WordEntropy(text))},
+		{"Mean word length", fmt.Sprintf("%.2f characters", voynich.MeanWordLength(text))},
+	}
+	if language, distance := voynich.ClosestLanguage(text); language != "" {
+		stats = append(stats, struct{ label, value string }{
+			"Closest language", fmt.Sprintf("%s (distance %.4f)", language, distance)})
+	}
+
+	for _, stat := range stats {
+		if _, err := fmt.Fprintf(stdout, "%-22s%s\n", stat.label+":", stat.value); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(stdout, "\nCharacter frequencies:\n%s",
+		voynich.RenderHistogram(voynich.CharCounts(text), histogramWidth))
+	return err
+}
+
+// histogramWidth is the length of the longest bar in the analyze chart.
+const histogramWidth = 40
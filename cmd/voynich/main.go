@@ -0,0 +1,138 @@
+// Command voynich encodes texts as bitstreams, decodes and analyzes them,
+// and searches the LZ77 parameter space for the decode with the lowest
+// entropy.
+//
+// Usage:
+//
+//	voynich encode [-input path] [-offset-bits n] [-length-bits n] [-raw]
+//	voynich decode [-bitstream path] [-offset-bits n] [-length-bits n] [-order MSB|LSB]
+//	        [-header-bits n] [-config path]
+//	voynich analyze [-input path]
+//	voynich [sweep] [-input path | -bitstream path] [-format table|json|csv] [-reverse]
+//	        [-metric shannon|conditional|ic|redundancy]
+//	        [-offset-bits list] [-length-bits list] [-quiet]
+//	        [-output path [-all]] [-min-bit-entropy bits]
+//
+// encode compresses a text into an LZ77 bitstream of '0' and '1'
+// characters, or with -raw writes its plain 8-bit encoding. decode
+// decompresses a bitstream with the given field widths, after skipping
+// -header-bits bits of header, or with the settings of a DecoderConfig
+// saved as JSON. analyze prints the entropy
+// and related statistics of a text. Texts are read from -input and
+// bitstreams from -bitstream, or from standard input when the flag is
+// absent.
+//
+// sweep, the default when no subcommand is given, encodes the text as an
+// 8-bit bitstream, or loads a ready-made bitstream with -bitstream, and
+// decodes it under every combination of field widths and bit orders. With
+// -format json or -format csv the sweep results are written in that
+// machine-readable form instead of the human-readable report. With
+// -reverse the sweep also decodes the bitstream read back to front. The
+// -metric flag selects the statistic that picks the best parameters and
+// fills the table's metric column. The field widths swept are given by
+// -offset-bits and -length-bits as comma-separated lists, 9,10,11 and
+// 3,4,5 by default. Progress is reported on standard error as each
+// combination finishes unless -quiet is given. With -output the best
+// decode is also saved to a file, or with -all every decode is saved into
+// that directory under a name such as decode_msb_o10_l4.txt. A warning is
+// printed on standard error when the bitstream's entropy is below
+// -min-bit-entropy bits per bit, 0.8 by default, or when it reads as 8-bit
+// ASCII text with every 8th bit 0, since such a stream is unlikely to be
+// compressed data.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/djabbat/voynich"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0) // Usage was already printed by the flag package
+		}
+		fmt.Fprintln(os.Stderr, "voynich:", err)
+		os.Exit(1)
+	}
+}
+
+// command runs one subcommand with the arguments that follow its name.
+type command func(args []string, stdin io.Reader, stdout, stderr io.Writer) error
+
+// commands maps subcommand names to their implementations.
+var commands = map[string]command{
+	"encode":  runEncode,
+	"decode":  runDecode,
+	"analyze": runAnalyze,
+	"sweep":   runSweep,
+}
+
+// run dispatches to the subcommand named by the first argument. Arguments
+// that do not start with a subcommand name are passed to sweep, so the
+// original flag-only invocation keeps working.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, ok := commands[args[0]]
+		if !ok {
+			return fmt.Errorf("unknown command %q (want encode, decode, analyze or sweep)", args[0])
+		}
+		return cmd(args[1:], stdin, stdout, stderr)
+	}
+	return runSweep(args, stdin, stdout, stderr)
+}
+
+// readInput returns the text to analyze, read from the file at path or from
+// stdin when path is empty. Empty and non-UTF-8 input is rejected.
+func readInput(path string, stdin io.Reader) (string, error) {
+	var data []byte
+	var err error
+	if path != "" {
+		data, err = os.ReadFile(path)
+	} else {
+		data, err = io.ReadAll(stdin)
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading input: %w", err)
+	}
+
+	if !utf8.Valid(data) {
+		return "", errors.New("input is not valid UTF-8")
+	}
+	text := string(data)
+	if strings.TrimSpace(text) == "" {
+		return "", errors.New("input is empty")
+	}
+	return text, nil
+}
+
+// readBitStream loads a bitstream of '0' and '1' characters from the file
+// at path, or from stdin when path is empty.
+func readBitStream(path string, stdin io.Reader) (string, error) {
+	var data []byte
+	var err error
+	if path != "" {
+		data, err = os.ReadFile(path)
+	} else {
+		data, err = io.ReadAll(stdin)
+		path = "standard input"
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading bitstream: %w", err)
+	}
+
+	bitStream, err := voynich.ParseBitStream(string(data))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	if bitStream == "" {
+		return "", errors.New("bitstream is empty")
+	}
+	return bitStream, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestRunRejectsBadArguments(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		stdin   string
+		wantErr string
+	}{
+		{"unknown command", []string{"frobnicate"}, "", "unknown command"},
+		{"undefined flag", []string{"-nonsense"}, "", "flag provided but not defined"},
+
+		{"encode bad width", []string{"encode", "-offset-bits", "ten"}, "", "invalid value"},
+		{"encode empty input", []string{"encode"}, "  \n", "input is empty"},
+		{"encode invalid width", []string{"encode", "-offset-bits", "0"}, "hello", "offset"},
+
+		{"decode unknown order", []string{"decode", "-order", "middle"}, "0101", "unknown bit order"},
+		{"decode missing config", []string{"decode", "-config", "/nonexistent/config.json"}, "0101", "reading config"},
+		{"decode bad bitstream", []string{"decode"}, "01x1", "invalid bitstream character"},
+		{"decode empty bitstream", []string{"decode"}, "\n", "bitstream is empty"},
+
+		{"analyze undefined flag", []string{"analyze", "-bitstream", "x"}, "", "flag provided but not defined"},
+		{"analyze empty input", []string{"analyze"}, "", "input is empty"},
+
+		{"sweep unknown format", []string{"sweep", "-format", "xml"}, "hello", "unknown format"},
+		{"sweep unknown metric", []string{"-metric", "length"}, "hello", "unknown metric"},
+		{"sweep width out of range", []string{"-offset-bits", "9,31"}, "hello", "not between 1 and 30"},
+		{"sweep all without output", []string{"-all"}, "hello", "-all requires -output"},
+		{"sweep input and bitstream", []string{"-input", "a.txt", "-bitstream", "b.txt"}, "", "cannot be used together"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			err := run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("run(%q) = %v, want an error containing %q", tt.args, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunHelp(t *testing.T) {
+	for _, args := range [][]string{{"-h"}, {"encode", "-h"}, {"decode", "-help"}} {
+		var stdout, stderr bytes.Buffer
+		if err := run(args, strings.NewReader(""), &stdout, &stderr); !errors.Is(err, flag.ErrHelp) {
+			t.Errorf("run(%q) = %v, want flag.ErrHelp", args, err)
+		}
+	}
+}
+
+func TestRunEncodeDecode(t *testing.T) {
+	const text = "the lazy dog sleeps while the lazy dog dreams"
+	var encoded, stderr bytes.Buffer
+	if err := run([]string{"encode", "-offset-bits", "9", "-length-bits", "3"}, strings.NewReader(text+"\n"), &encoded, &stderr); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded bytes.Buffer
+	if err := run([]string{"decode", "-offset-bits", "9", "-length-bits", "3"}, &encoded, &decoded, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSuffix(decoded.String(), "\n"); got != text {
+		t.Errorf("decoded %q, want %q", got, text)
+	}
+}
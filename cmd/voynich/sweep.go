@@ -0,0 +1,405 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/djabbat/voynich"
+)
+
+// runSweep parses the sweep flags and performs the parameter sweep,
+// writing the report to stdout and progress to stderr.
+func runSweep(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("voynich sweep", flag.ContinueOnError)
+	inputPath := flags.String("input", "", "read the text from `path` instead of standard input")
+	bitStreamPath := flags.String("bitstream", "", "decode the '0'/'1' bitstream in `path` instead of encoding a text")
+	format := flags.String("format", "table", "output `format`: table, json or csv")
+	reverse := flags.Bool("reverse", false, "also sweep the bitstream read back to front")
+	metricName := flags.String("metric", "shannon", "ranking `metric`: shannon, conditional, ic or redundancy")
+	offsetBitsFlag := flags.String("offset-bits", "9,10,11", "comma-separated offset field `widths` to sweep")
+	lengthBitsFlag := flags.String("length-bits", "3,4,5", "comma-separated length field `widths` to sweep")
+	quiet := flags.Bool("quiet", false, "do not report sweep progress on standard error")
+	outputPath := flags.String("output", "", "write the best decode to the file at `path`")
+	allOutputs := flags.Bool("all", false, "with -output, write every decode into the directory at path")
+	minBitEntropy := flags.Float64("min-bit-entropy", defaultMinBitEntropy,
+		"warn when the bitstream's entropy is below `bits` per bit")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	switch *format {
+	case "table", "json", "csv":
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	metric, ok := metrics[*metricName]
+	if !ok {
+		return fmt.Errorf("unknown metric %q", *metricName)
+	}
+	offsetBitsOptions, err := parseWidths("-offset-bits", *offsetBitsFlag, maxOffsetBits)
+	if err != nil {
+		return err
+	}
+	lengthBitsOptions, err := parseWidths("-length-bits", *lengthBitsFlag, maxLengthBits)
+	if err != nil {
+		return err
+	}
+
+	if *allOutputs && *outputPath == "" {
+		return errors.New("-all requires -output")
+	}
+	if *inputPath != "" && *bitStreamPath != "" {
+		return errors.New("-input and -bitstream cannot be used together")
+	}
+
+	var text, bitStream string
+	if *bitStreamPath != "" {
+		if bitStream, err = readBitStream(*bitStreamPath, stdin); err != nil {
+			return err
+		}
+	} else {
+		if text, err = readInput(*inputPath, stdin); err != nil {
+			return err
+		}
+
+		// Generate bitstream from the input text
+		bitStream = voynich.GenerateBitStream(text)
+	}
+
+	// Compressed data is close to random at the bit level
+	if bitEntropy := voynich.BitEntropy(bitStream); bitEntropy < *minBitEntropy {
+		fmt.Fprintf(stderr, "voynich: warning: bitstream entropy %.4f bits/bit is below %.4f; "+
+			"it is probably not compressed data\n", bitEntropy, *minBitEntropy)
+	} else if looksLikeASCII(bitStream) {
+		fmt.Fprintln(stderr, "voynich: warning: every 8th bit of the bitstream is 0, as in 8-bit ASCII text; "+
+			"it is probably not compressed data")
+	}
+
+	// Test parameters for LZ77 decompression
+	bitOrderOptions := []voynich.BitOrder{voynich.MSBFirst, voynich.LSBFirst}
+
+	directions := []bool{false}
+	if *reverse {
+		directions = append(directions, true)
+	}
+
+	// Report each finished combination as [done/total]
+	var progress func(voynich.SweepResult)
+	if !*quiet {
+		done := 0
+		total := len(directions) * len(bitOrderOptions) * len(offsetBitsOptions) * len(lengthBitsOptions)
+		progress = func(result voynich.SweepResult) {
+			done++
+			fmt.Fprintf(stderr, "[%d/%d] order=%s offsetBits=%d lengthBits=%d\n",
+				done, total, result.BitOrder, result.OffsetBits, result.LengthBits)
+		}
+	}
+
+	// Test all parameter combinations
+	var results []voynich.SweepResult
+	for _, reversed := range directions {
+		stream := bitStream
+		if reversed {
+			stream = voynich.ReverseBits(bitStream)
+		}
+		for _, bitOrder := range bitOrderOptions {
+			opts := voynich.DecodeOptions{BitOrder: bitOrder}
+			sweep := voynich.SweepLZ77WithProgress(stream, offsetBitsOptions, lengthBitsOptions, opts, progress)
+			for i := range sweep {
+				sweep[i].Reversed = reversed
+			}
+			results = append(results, sweep...)
+		}
+	}
+
+	// The report comes first so that saving decodes never suppresses it
+	switch *format {
+	case "json":
+		err = writeJSON(stdout, results)
+	case "csv":
+		err = writeCSV(stdout, results)
+	default:
+		writeTable(stdout, text, bitStream, results, metric)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *outputPath != "" {
+		return writeDecodes(*outputPath, *allOutputs, results, metric, stderr)
+	}
+	return nil
+}
+
+// defaultMinBitEntropy is the bit entropy below which the sweep warns that
+// its input does not look compressed: a stream below it is dominated by one
+// bit value. Plain ASCII text stays near 0.99 bits/bit, too close to
+// compressed data for any entropy threshold to separate them, so it is
+// caught by looksLikeASCII instead.
+const defaultMinBitEntropy = 0.8
+
+// minASCIIBytes is the number of bytes looksLikeASCII needs before it
+// trusts the pattern; random data has every 8th bit 0 over 16 bytes only
+// once in 65536 streams.
+const minASCIIBytes = 16
+
+// looksLikeASCII reports whether the bitstream reads as 8-bit ASCII: at
+// least minASCIIBytes whole bytes, each with its high bit 0.
+func looksLikeASCII(bitStream string) bool {
+	if len(bitStream) < minASCIIBytes*8 {
+		return false
+	}
+	for i := 0; i+8 <= len(bitStream); i += 8 {
+		if bitStream[i] != '0' {
+			return false
+		}
+	}
+	return true
+}
+
+// writeDecodes saves decoded outputs to files. By default the best result
+// by metric is written to path; with all, path is a directory that receives
+// one file per result, named by its parameters as decodeFileName does.
+// Failed decodes are written with the output they reached; when even the
+// best result failed, a warning saying so is printed on stderr.
+func writeDecodes(path string, all bool, results []voynich.SweepResult, metric rankingMetric, stderr io.Writer) error {
+	if !all {
+		ranked := voynich.RankSweep(results, metric.weights)
+		if len(ranked) == 0 {
+			return nil
+		}
+		if ranked[0].Err != nil {
+			fmt.Fprintf(stderr, "voynich: warning: no parameter combination decoded without error; "+
+				"saving the partial output of the best one to %s\n", path)
+		}
+		return os.WriteFile(path, []byte(ranked[0].Output), 0o644)
+	}
+
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return err
+	}
+	for _, result := range results {
+		name := filepath.Join(path, decodeFileName(result))
+		if err := os.WriteFile(name, []byte(result.Output), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeFileName names the file holding one result's decode, such as
+// "decode_msb_o10_l4.txt", with "_r" before the extension for decodes of
+// the reversed bitstream.
+func decodeFileName(result voynich.SweepResult) string {
+	name := fmt.Sprintf("decode_%s_o%d_l%d", strings.ToLower(result.BitOrder.String()), result.OffsetBits, result.LengthBits)
+	if result.Reversed {
+		name += "_r"
+	}
+	return name + ".txt"
+}
+
+// maxOffsetBits and maxLengthBits bound the field widths accepted on the
+// command line. A 16-bit length field already allows copies of 65535
+// symbols; wider ones are almost certainly a typo.
+const (
+	maxOffsetBits = 30
+	maxLengthBits = 16
+)
+
+// parseWidths parses a comma-separated list of field widths given to the
+// named flag, such as "8,9,10,12". Every width must be between 1 and limit
+// and appear only once.
+func parseWidths(name, value string, limit int) ([]int, error) {
+	var widths []int
+	seen := make(map[int]bool)
+	for _, field := range strings.Split(value, ",") {
+		width, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid width %q", name, field)
+		}
+		if width < 1 || width > limit {
+			return nil, fmt.Errorf("%s: width %d is not between 1 and %d", name, width, limit)
+		}
+		if seen[width] {
+			return nil, fmt.Errorf("%s: width %d is listed twice", name, width)
+		}
+		seen[width] = true
+		widths = append(widths, width)
+	}
+	return widths, nil
+}
+
+// rankingMetric is a statistic the sweep can be ranked by.
+type rankingMetric struct {
+	header  string               // Table column heading, at most 7 characters
+	best    string               // Label for the best result's value
+	unit    string               // Unit printed after the best value
+	value   func(string) float64 // Computes the metric of a decode
+	weights voynich.ScoreWeights // Ranks results so the best comes first
+}
+
+// metrics maps the -metric flag values to their statistics.
+var metrics = map[string]rankingMetric{
+	"shannon": {
+		header: "Entropy", best: "Lowest entropy", unit: " bits/character",
+		value: voynich.ShannonEntropy, weights: voynich.DefaultScoreWeights,
+	},
+	"conditional": {
+		header: "CondEnt", best: "Lowest conditional entropy", unit: " bits/character",
+		value: voynich.ConditionalEntropy, weights: voynich.ScoreWeights{ConditionalEntropy: 1},
+	},
+	"ic": {
+		header: "IC", best: "Highest index of coincidence",
+		value: voynich.IndexOfCoincidence, weights: voynich.ScoreWeights{IndexOfCoincidence: 1},
+	},
+	"redundancy": {
+		header: "Redund", best: "Highest redundancy",
+		value: voynich.Redundancy, weights: voynich.ScoreWeights{Redundancy: 1},
+	},
+}
+
+// sampleLength is the number of output characters shown for each result.
+const sampleLength = 20
+
+// sample truncates a decode to sampleLength characters for display.
+func sample(output string) string {
+	runes := []rune(output)
+	if len(runes) <= sampleLength {
+		return output
+	}
+	return string(runes[:sampleLength]) + "..."
+}
+
+// jsonResult is the JSON form of one sweep result.
+type jsonResult struct {
+	BitOrder   string  `json:"bit_order"`
+	Reversed   bool    `json:"reversed"`
+	OffsetBits int     `json:"offset_bits"`
+	LengthBits int     `json:"length_bits"`
+	Entropy    float64 `json:"entropy"`
+	OutputLen  int     `json:"output_len"`
+	Sample     string  `json:"sample"`
+	Error      string  `json:"error"`
+}
+
+// writeJSON writes the sweep results as an indented JSON array. A failed
+// combination is reported through its error field rather than aborting.
+func writeJSON(w io.Writer, results []voynich.SweepResult) error {
+	records := make([]jsonResult, len(results))
+	for i, result := range results {
+		records[i] = jsonResult{
+			BitOrder:   result.BitOrder.String(),
+			Reversed:   result.Reversed,
+			OffsetBits: result.OffsetBits,
+			LengthBits: result.LengthBits,
+			Entropy:    result.Entropy,
+			OutputLen:  utf8.RuneCountInString(result.Output),
+			Sample:     sample(result.Output),
+		}
+		if result.Err != nil {
+			records[i].Error = result.Err.Error()
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
+
+// csvHeader lists the CSV columns in the order rows are written.
+var csvHeader = []string{"BitOrder", "Reversed", "OffsetBits", "LengthBits", "Entropy", "OutputLen", "Error"}
+
+// writeCSV writes a header row and then one row per sweep result, streaming
+// each row to w as it is formatted.
+func writeCSV(w io.Writer, results []voynich.SweepResult) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, result := range results {
+		errText := ""
+		if result.Err != nil {
+			errText = result.Err.Error()
+		}
+		row := []string{
+			result.BitOrder.String(),
+			strconv.FormatBool(result.Reversed),
+			strconv.Itoa(result.OffsetBits),
+			strconv.Itoa(result.LengthBits),
+			strconv.FormatFloat(result.Entropy, 'f', 6, 64),
+			strconv.Itoa(utf8.RuneCountInString(result.Output)),
+			errText,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeTable writes the human-readable sweep report. The text is empty when
+// the bitstream was loaded directly, and the report then omits it.
+func writeTable(stdout io.Writer, text, bitStream string, results []voynich.SweepResult, metric rankingMetric) {
+	if text != "" {
+		fmt.Fprintf(stdout, "Original text: %s\n\n", text)
+	}
+	fmt.Fprintf(stdout, "Bitstream (%d bits):\n%s\n\n", len(bitStream), bitStream)
+
+	fmt.Fprintln(stdout, "Testing LZ77 parameters:")
+	fmt.Fprintf(stdout, "Order | OffsetBits | LengthBits | %-7s | Output Sample\n", metric.header)
+	fmt.Fprintln(stdout, "------|------------|------------|---------|---------------")
+
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Fprintf(stdout, "%5s | %10d | %10d | %7s | Error: %v\n",
+				orderLabel(result), result.OffsetBits, result.LengthBits, "N/A", result.Err)
+			continue
+		}
+
+		fmt.Fprintf(stdout, "%5s | %10d | %10d | %7.4f | %s\n",
+			orderLabel(result), result.OffsetBits, result.LengthBits, metric.value(result.Output), sample(result.Output))
+	}
+
+	// Display best result by the selected metric
+	ranked := voynich.RankSweep(results, metric.weights)
+	if len(ranked) == 0 || ranked[0].Err != nil {
+		fmt.Fprintln(stdout, "\nNo parameter combination decoded without error.")
+		return
+	}
+	best := ranked[0]
+	fmt.Fprintf(stdout, "\nBest parameters: bitOrder=%s, reversed=%t, offsetBits=%d, lengthBits=%d\n",
+		best.BitOrder, best.Reversed, best.OffsetBits, best.LengthBits)
+	fmt.Fprintf(stdout, "%s: %.4f%s\n", metric.best, metric.value(best.Output), metric.unit)
+	fmt.Fprintf(stdout, "Decompressed result (%d characters):\n%s\n",
+		utf8.RuneCountInString(best.Output), best.Output)
+
+	// Entropy analysis
+	fmt.Fprintf(stdout, "\nEntropy comparison:\n")
+	if text != "" {
+		originalEntropy := voynich.ShannonEntropy(text)
+		fmt.Fprintf(stdout, "Original text:  %.4f bits/character\n", originalEntropy)
+	}
+	fmt.Fprintf(stdout, "Decompressed:   %.4f bits/character\n", best.Entropy)
+	fmt.Fprintf(stdout, "Raw 8-bit:      %.4f bits/character\n",
+		voynich.ShannonEntropy(voynich.DecodeRaw(bitStream, 8)))
+	fmt.Fprintf(stdout, "Bitstream:      %.4f bits/bit\n",
+		voynich.BitEntropy(bitStream))
+}
+
+// orderLabel names the bit order of a result for the table, marking
+// decodes of the reversed bitstream with an "-R" suffix.
+func orderLabel(result voynich.SweepResult) string {
+	if result.Reversed {
+		return result.BitOrder.String() + "-R"
+	}
+	return result.BitOrder.String()
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/djabbat/voynich"
+)
+
+func TestWriteJSON(t *testing.T) {
+	results := []voynich.SweepResult{
+		{OffsetBits: 10, LengthBits: 4, DecodeResult: voynich.DecodeResult{Output: strings.Repeat("ab", 20)}, Entropy: 1},
+		{OffsetBits: 11, LengthBits: 5, BitOrder: voynich.LSBFirst, Reversed: true, Err: errors.New("incomplete literal at position 3")},
+	}
+	var out bytes.Buffer
+	if err := writeJSON(&out, results); err != nil {
+		t.Fatal(err)
+	}
+
+	var records []map[string]any
+	if err := json.Unmarshal(out.Bytes(), &records); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, out.String())
+	}
+	if len(records) != len(results) {
+		t.Fatalf("got %d records, want %d", len(records), len(results))
+	}
+	for i, record := range records {
+		for _, field := range []string{"bit_order", "reversed", "offset_bits", "length_bits", "entropy", "output_len", "sample", "error"} {
+			if _, ok := record[field]; !ok {
+				t.Errorf("record %d has no %q field: %v", i, field, record)
+			}
+		}
+	}
+
+	first, second := records[0], records[1]
+	if first["offset_bits"] != 10.0 || first["output_len"] != 40.0 || first["sample"] != strings.Repeat("ab", 10)+"..." || first["error"] != "" {
+		t.Errorf("unexpected first record %v", first)
+	}
+	if second["bit_order"] != "LSB" || second["reversed"] != true || second["error"] != "incomplete literal at position 3" {
+		t.Errorf("unexpected second record %v", second)
+	}
+}
+
+func TestSweepFormatJSON(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	args := []string{"-format", "json", "-quiet", "-offset-bits", "9,10", "-length-bits", "4"}
+	if err := run(args, strings.NewReader("hello voynich"), &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	var records []jsonResult
+	if err := json.Unmarshal(stdout.Bytes(), &records); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, stdout.String())
+	}
+	// Two offset widths, one length width and both bit orders
+	if len(records) != 4 {
+		t.Errorf("got %d records, want 4", len(records))
+	}
+}
+
+func TestMetricChangesBest(t *testing.T) {
+	// The alternating decode has the higher entropy but is perfectly
+	// predictable from the previous character
+	results := []voynich.SweepResult{
+		{OffsetBits: 9, LengthBits: 4, DecodeResult: voynich.DecodeResult{Output: "abababab"}},
+		{OffsetBits: 10, LengthBits: 4, DecodeResult: voynich.DecodeResult{Output: "aaaaaaab"}},
+	}
+	tests := []struct {
+		metric string
+		want   string
+	}{
+		{metric: "shannon", want: "offsetBits=10, lengthBits=4"},
+		{metric: "conditional", want: "offsetBits=9, lengthBits=4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.metric, func(t *testing.T) {
+			var out bytes.Buffer
+			writeTable(&out, "", "0101", results, metrics[tt.metric])
+			if !strings.Contains(out.String(), "Best parameters: bitOrder=MSB, reversed=false, "+tt.want+"\n") {
+				t.Errorf("report does not pick %s:\n%s", tt.want, out.String())
+			}
+			if !strings.Contains(out.String(), metrics[tt.metric].best+":") {
+				t.Errorf("report does not label the best value with %q:\n%s", metrics[tt.metric].best, out.String())
+			}
+		})
+	}
+
+	err := run([]string{"-metric", "perplexity"}, strings.NewReader("text"), io.Discard, io.Discard)
+	if err == nil || err.Error() != `unknown metric "perplexity"` {
+		t.Errorf("error = %v, want the unknown metric rejected", err)
+	}
+}
+
+func TestParseWidths(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []int
+		wantErr string
+	}{
+		{value: "9,10,11", want: []int{9, 10, 11}},
+		{value: " 12 , 8", want: []int{12, 8}},
+		{value: "16", want: []int{16}},
+		{value: "9,x", wantErr: `-offset-bits: invalid width "x"`},
+		{value: "9,,10", wantErr: `-offset-bits: invalid width ""`},
+		{value: "0", wantErr: "-offset-bits: width 0 is not between 1 and 16"},
+		{value: "17", wantErr: "-offset-bits: width 17 is not between 1 and 16"},
+		{value: "9,10,9", wantErr: "-offset-bits: width 9 is listed twice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseWidths("-offset-bits", tt.value, 16)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteDecodes(t *testing.T) {
+	results := []voynich.SweepResult{
+		{OffsetBits: 9, LengthBits: 4, DecodeResult: voynich.DecodeResult{Output: "abcdefgh"}},
+		{OffsetBits: 10, LengthBits: 4, DecodeResult: voynich.DecodeResult{Output: "aaaaaaab"}},
+		{OffsetBits: 10, LengthBits: 4, BitOrder: voynich.LSBFirst, Reversed: true,
+			DecodeResult: voynich.DecodeResult{Output: "partial"}, Err: errors.New("incomplete literal at position 3")},
+	}
+
+	t.Run("best", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "best.txt")
+		var stderr bytes.Buffer
+		if err := writeDecodes(path, false, results, metrics["shannon"], &stderr); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "aaaaaaab" {
+			t.Errorf("wrote %q, want the lowest-entropy decode %q", data, "aaaaaaab")
+		}
+		if stderr.Len() != 0 {
+			t.Errorf("unexpected warning %q", stderr.String())
+		}
+	})
+
+	t.Run("all", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "decodes") // Created by writeDecodes
+		if err := writeDecodes(dir, true, results, metrics["shannon"], io.Discard); err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]string{
+			"decode_msb_o9_l4.txt":    "abcdefgh",
+			"decode_msb_o10_l4.txt":   "aaaaaaab",
+			"decode_lsb_o10_l4_r.txt": "partial", // Failed decodes keep their partial output
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != len(want) {
+			t.Errorf("wrote %d files, want %d", len(entries), len(want))
+		}
+		for name, content := range want {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				t.Error(err)
+				continue
+			}
+			if string(data) != content {
+				t.Errorf("%s contains %q, want %q", name, data, content)
+			}
+		}
+	})
+
+	t.Run("best failed", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "best.txt")
+		var stderr bytes.Buffer
+		if err := writeDecodes(path, false, results[2:], metrics["shannon"], &stderr); err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != "partial" {
+			t.Errorf("wrote %q, want the partial output %q", data, "partial")
+		}
+		if !strings.Contains(stderr.String(), "no parameter combination decoded without error") {
+			t.Errorf("stderr %q does not warn that the best decode failed", stderr.String())
+		}
+	})
+}
+
+func TestSweepOutput(t *testing.T) {
+	dir := t.TempDir()
+	args := []string{"-quiet", "-offset-bits", "9,10", "-length-bits", "4", "-output", filepath.Join(dir, "best.txt")}
+	if err := run(args, strings.NewReader("hello voynich"), io.Discard, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "best.txt")); err != nil {
+		t.Errorf("-output did not write the best decode: %v", err)
+	}
+
+	args = []string{"-quiet", "-offset-bits", "9,10", "-length-bits", "4", "-output", filepath.Join(dir, "all"), "-all"}
+	if err := run(args, strings.NewReader("hello voynich"), io.Discard, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "all"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Two offset widths, one length width and both bit orders
+	if len(entries) != 4 {
+		t.Errorf("-all wrote %d files, want 4", len(entries))
+	}
+}
+
+func TestSweepWarnsAboutUncompressedInput(t *testing.T) {
+	dir := t.TempDir()
+	writeBits := func(name, bits string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(bits), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	lowEntropy := writeBits("low.txt", strings.Repeat("1111111111111110", 32))
+	random := writeBits("random.txt", voynich.RandomBitStream(4096, 1))
+
+	const entropyWarning = "voynich: warning: bitstream entropy"
+	const asciiWarning = "voynich: warning: every 8th bit of the bitstream is 0"
+	tests := []struct {
+		name  string
+		args  []string
+		stdin string
+		want  string // Empty when no warning is expected
+	}{
+		{"ascii text", nil, "the quick brown fox jumps over the lazy dog", asciiWarning},
+		{"low entropy", []string{"-bitstream", lowEntropy}, "", entropyWarning},
+		{"threshold lowered", []string{"-bitstream", lowEntropy, "-min-bit-entropy", "0"}, "", ""},
+		{"random", []string{"-bitstream", random}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			args := append([]string{"-quiet", "-offset-bits", "9", "-length-bits", "3"}, tt.args...)
+			if err := run(args, strings.NewReader(tt.stdin), io.Discard, &stderr); err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == "" {
+				if stderr.Len() != 0 {
+					t.Errorf("unexpected warning %q", stderr.String())
+				}
+			} else if !strings.HasPrefix(stderr.String(), tt.want) {
+				t.Errorf("stderr %q does not start with %q", stderr.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestLooksLikeASCII(t *testing.T) {
+	text := voynich.GenerateBitStream("sixteen bytes!!!")
+	tests := []struct {
+		name      string
+		bitStream string
+		want      bool
+	}{
+		{"ascii", text, true},
+		{"too short", text[:len(text)-8], false},
+		{"high bit set", "1" + text[1:], false},
+		{"trailing partial byte", text + "101", true},
+	}
+	for _, tt := range tests {
+		if got := looksLikeASCII(tt.bitStream); got != tt.want {
+			t.Errorf("%s: looksLikeASCII = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package voynich
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ComparisonReport summarizes how two decodes of the same bitstream, for
+// example under neighbouring parameters, differ. Deltas are B minus A.
+type ComparisonReport struct {
+	EntropyA, EntropyB float64
+	EntropyDelta       float64
+	LengthA, LengthB   int // In characters
+	LengthDelta        int
+	CommonPrefix       int     // Leading characters the outputs share
+	MatchRatio         float64 // Fraction of positions holding the same character
+}
+
+// CompareDecodes compares the outputs of two decodes character by
+// character. MatchRatio counts the positions where both outputs hold the
+// same character, divided by the length of the longer output, so it stays
+// linear in the output size where an edit distance would be quadratic. Two
+// empty outputs have a MatchRatio of 1.
+func CompareDecodes(a, b DecodeResult) ComparisonReport {
+	runesA, runesB := []rune(a.Output), []rune(b.Output)
+	report := ComparisonReport{
+		EntropyA:    ShannonEntropy(a.Output),
+		EntropyB:    ShannonEntropy(b.Output),
+		LengthA:     len(runesA),
+		LengthB:     len(runesB),
+		LengthDelta: len(runesB) - len(runesA),
+	}
+	report.EntropyDelta = report.EntropyB - report.EntropyA
+
+	shorter, longer := len(runesA), len(runesB)
+	if shorter > longer {
+		shorter, longer = longer, shorter
+	}
+	if longer == 0 {
+		report.MatchRatio = 1
+		return report
+	}
+
+	matches := 0
+	prefixEnded := false
+	for i := 0; i < shorter; i++ {
+		if runesA[i] == runesB[i] {
+			matches++
+			if !prefixEnded {
+				report.CommonPrefix++
+			}
+		} else {
+			prefixEnded = true
+		}
+	}
+	report.MatchRatio = float64(matches) / float64(longer)
+	return report
+}
+
+// String renders the report as a short text block, one statistic per line.
+func (r ComparisonReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-22s%.4f -> %.4f (%+.4f) bits/character\n", "Entropy:", r.EntropyA, r.EntropyB, r.EntropyDelta)
+	fmt.Fprintf(&b, "%-22s%d -> %d (%+d) characters\n", "Length:", r.LengthA, r.LengthB, r.LengthDelta)
+	fmt.Fprintf(&b, "%-22s%d characters\n", "Common prefix:", r.CommonPrefix)
+	fmt.Fprintf(&b, "%-22s%.4f\n", "Matching characters:", r.MatchRatio)
+	return b.String()
+}
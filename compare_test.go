@@ -0,0 +1,51 @@
+package voynich
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompareDecodes(t *testing.T) {
+	a := DecodeResult{Output: "aaaa"}
+	b := DecodeResult{Output: "aabbab"}
+	report := CompareDecodes(a, b)
+	want := ComparisonReport{
+		EntropyA: 0, EntropyB: 1, EntropyDelta: 1,
+		LengthA: 4, LengthB: 6, LengthDelta: 2,
+		CommonPrefix: 2,
+		MatchRatio:   2.0 / 6, // Two matches over the longer output
+	}
+	if math.Abs(report.MatchRatio-want.MatchRatio) > 1e-12 {
+		t.Errorf("MatchRatio = %v, want %v", report.MatchRatio, want.MatchRatio)
+	}
+	report.MatchRatio = want.MatchRatio
+	if report != want {
+		t.Errorf("got %+v, want %+v", report, want)
+	}
+
+	// Swapping the decodes negates the deltas
+	if swapped := CompareDecodes(b, a); swapped.EntropyDelta != -1 || swapped.LengthDelta != -2 {
+		t.Errorf("swapped deltas %v and %d, want -1 and -2", swapped.EntropyDelta, swapped.LengthDelta)
+	}
+
+	// Matches after the first difference count toward the ratio but not the prefix
+	report = CompareDecodes(DecodeResult{Output: "abcd"}, DecodeResult{Output: "xbcd"})
+	if report.CommonPrefix != 0 || report.MatchRatio != 0.75 {
+		t.Errorf("got prefix %d and ratio %v, want 0 and 0.75", report.CommonPrefix, report.MatchRatio)
+	}
+
+	if report := CompareDecodes(DecodeResult{}, DecodeResult{}); report.MatchRatio != 1 || report.CommonPrefix != 0 {
+		t.Errorf("empty decodes: got %+v, want a MatchRatio of 1", report)
+	}
+}
+
+func TestComparisonReportString(t *testing.T) {
+	report := CompareDecodes(DecodeResult{Output: "aaaa"}, DecodeResult{Output: "aabbab"})
+	want := "Entropy:              0.0000 -> 1.0000 (+1.0000) bits/character\n" +
+		"Length:               4 -> 6 (+2) characters\n" +
+		"Common prefix:        2 characters\n" +
+		"Matching characters:  0.3333\n"
+	if got := report.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
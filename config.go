@@ -0,0 +1,160 @@
+package voynich
+
+import (
+	"fmt"
+	"io"
+)
+
+// DecoderConfig is a complete, serializable description of an LZ77 decode:
+// the field widths together with every DecodeOptions setting that can be
+// written down. Save it as JSON to share or reproduce a run. Literal
+// filters and trace callbacks cannot be serialized; use DecodeOptions
+// directly when they are needed.
+type DecoderConfig struct {
+	OffsetBits     int        `json:"offset_bits"`
+	LengthBits     int        `json:"length_bits"`
+	BitOrder       BitOrder   `json:"bit_order"`
+	LiteralBits    int        `json:"literal_bits,omitempty"`
+	Alphabet       string     `json:"alphabet,omitempty"` // Symbols in code order; empty for character codes
+	WindowSize     int        `json:"window_size,omitempty"`
+	OffsetMode     OffsetMode `json:"offset_mode"`
+	MinMatch       int        `json:"min_match,omitempty"`
+	Strict         bool       `json:"strict,omitempty"`
+	MaxOutput      int        `json:"max_output,omitempty"`
+	MaxCorrections int        `json:"max_corrections,omitempty"`
+	EOSPattern     string     `json:"eos_pattern,omitempty"`
+
+	// HeaderBits skips a fixed-size header at the start of the stream;
+	// decoding starts after it, while reported positions still count from
+	// the start of the whole stream. StripBits returns the header for
+	// inspection.
+	HeaderBits int `json:"header_bits,omitempty"`
+}
+
+// Options returns the DecodeOptions described by the config.
+func (c DecoderConfig) Options() DecodeOptions {
+	opts := DecodeOptions{
+		BitOrder:       c.BitOrder,
+		LiteralBits:    c.LiteralBits,
+		WindowSize:     c.WindowSize,
+		OffsetMode:     c.OffsetMode,
+		MinMatch:       c.MinMatch,
+		Strict:         c.Strict,
+		MaxOutput:      c.MaxOutput,
+		MaxCorrections: c.MaxCorrections,
+		EOSPattern:     c.EOSPattern,
+	}
+	if c.Alphabet != "" {
+		opts.Alphabet = []rune(c.Alphabet)
+	}
+	return opts
+}
+
+// DecodeWithConfig decodes a bitstream as described by cfg. It is
+// DecodeLZ77WithOptions with the field widths and options taken from one
+// value, after skipping cfg.HeaderBits. A stream shorter than its header
+// is an error.
+func DecodeWithConfig(bitStream string, cfg DecoderConfig) (DecodeResult, error) {
+	decoder, body, err := cfg.newDecoder(bitStream)
+	if err != nil {
+		return DecodeResult{}, err
+	}
+	return decoder.decode(body)
+}
+
+// newDecoder validates the config and returns a decoder for it together
+// with the bits that follow the header.
+func (c DecoderConfig) newDecoder(bitStream string) (*lz77Decoder, string, error) {
+	if c.HeaderBits < 0 {
+		return nil, "", fmt.Errorf("HeaderBits must not be negative, got %d", c.HeaderBits)
+	}
+	if c.HeaderBits > len(bitStream) {
+		return nil, "", fmt.Errorf("bitstream of %d bits is shorter than the %d-bit header", len(bitStream), c.HeaderBits)
+	}
+	decoder, err := newLZ77Decoder(c.OffsetBits, c.LengthBits, c.Options())
+	if err != nil {
+		return nil, "", err
+	}
+	decoder.base = c.HeaderBits
+	return decoder, bitStream[c.HeaderBits:], nil
+}
+
+// DecodeLZ77To is DecodeWithConfig that writes the decoded output to w as
+// it is produced instead of returning it, so a long decode can go straight
+// to a file or a hash without being held in memory; only the sliding window
+// is kept. The returned result carries the command statistics with an
+// empty Output. On a decode error everything decoded before it has been
+// written; a write error stops the decode and is returned as is.
+func DecodeLZ77To(w io.Writer, bitStream string, cfg DecoderConfig) (DecodeResult, error) {
+	decoder, body, err := cfg.newDecoder(bitStream)
+	if err != nil {
+		return DecodeResult{}, err
+	}
+
+	position := 0
+	for position < len(body) {
+		if stop, _ := decoder.endOfStream(body, position, true); stop {
+			break
+		}
+		if position, err = decoder.step(body, position); err != nil {
+			break
+		}
+		// Write in blocks rather than after every command
+		if decoder.output.Len() >= writeBlockSize {
+			if _, werr := decoder.output.WriteTo(w); werr != nil {
+				return decoder.stats, werr
+			}
+		}
+	}
+
+	if _, werr := decoder.output.WriteTo(w); werr != nil {
+		return decoder.stats, werr
+	}
+	return decoder.stats, err
+}
+
+// writeBlockSize is the amount of decoded output DecodeLZ77To buffers
+// before writing it out.
+const writeBlockSize = 32 << 10
+
+// MarshalText encodes the bit order by its short name, "MSB" or "LSB".
+func (o BitOrder) MarshalText() ([]byte, error) {
+	if o != MSBFirst && o != LSBFirst {
+		return nil, fmt.Errorf("invalid bit order %d", int(o))
+	}
+	return []byte(o.String()), nil
+}
+
+// UnmarshalText decodes a bit order written by MarshalText.
+func (o *BitOrder) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "MSB":
+		*o = MSBFirst
+	case "LSB":
+		*o = LSBFirst
+	default:
+		return fmt.Errorf("unknown bit order %q", text)
+	}
+	return nil
+}
+
+// MarshalText encodes the offset mode by its short name, "end" or "start".
+func (m OffsetMode) MarshalText() ([]byte, error) {
+	if m != FromEnd && m != FromStart {
+		return nil, fmt.Errorf("invalid offset mode %d", int(m))
+	}
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText decodes an offset mode written by MarshalText.
+func (m *OffsetMode) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "end":
+		*m = FromEnd
+	case "start":
+		*m = FromStart
+	default:
+		return fmt.Errorf("unknown offset mode %q", text)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package voynich
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecoderConfigJSONRoundTrip(t *testing.T) {
+	cfg := DecoderConfig{
+		OffsetBits:  10,
+		LengthBits:  4,
+		BitOrder:    LSBFirst,
+		LiteralBits: 7,
+		Alphabet:    "abc",
+		WindowSize:  512,
+		OffsetMode:  FromStart,
+		MinMatch:    2,
+		Strict:      true,
+		MaxOutput:   1000,
+		EOSPattern:  "0000",
+		HeaderBits:  16,
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The enums are written by name, not by number
+	for _, want := range []string{`"bit_order":"LSB"`, `"offset_mode":"start"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("%s does not contain %s", data, want)
+		}
+	}
+
+	var got DecoderConfig
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, cfg) {
+		t.Errorf("round trip gave %+v, want %+v", got, cfg)
+	}
+}
+
+func TestDecoderConfigJSONRejectsUnknownNames(t *testing.T) {
+	for _, data := range []string{
+		`{"offset_bits":10,"length_bits":4,"bit_order":"middle","offset_mode":"end"}`,
+		`{"offset_bits":10,"length_bits":4,"bit_order":"MSB","offset_mode":"sideways"}`,
+	} {
+		var cfg DecoderConfig
+		if err := json.Unmarshal([]byte(data), &cfg); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded, want an error", data)
+		}
+	}
+
+	if _, err := json.Marshal(DecoderConfig{BitOrder: BitOrder(7)}); err == nil {
+		t.Error("Marshal of an invalid bit order succeeded, want an error")
+	}
+}
+
+func TestDecodeWithReloadedConfig(t *testing.T) {
+	bitStream, err := EncodeLZ77(phaseText, 10, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bitStream = "1010101010101010" + bitStream
+
+	data, err := json.Marshal(DecoderConfig{OffsetBits: 10, LengthBits: 4, HeaderBits: 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cfg DecoderConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := DecodeWithConfig(bitStream, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != phaseText {
+		t.Errorf("decoded %q, want %q", result.Output, phaseText)
+	}
+}
+
+func TestDecodeLZ77To(t *testing.T) {
+	// Long enough to be written out in several blocks
+	text := benchText(3 * writeBlockSize)
+	bitStream, err := EncodeLZ77(text, 12, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := DecoderConfig{OffsetBits: 12, LengthBits: 5}
+
+	want, err := DecodeWithConfig(bitStream, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	stats, err := DecodeLZ77To(&out, bitStream, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != want.Output || out.String() != text {
+		t.Errorf("wrote %d characters that differ from the string decode", out.Len())
+	}
+	// The statistics match the string decode apart from the empty Output
+	want.Output = ""
+	if stats != want {
+		t.Errorf("got statistics %+v, want %+v", stats, want)
+	}
+
+	// On a decode error the output up to it has still been written
+	out.Reset()
+	truncated := bitStream[:len(bitStream)-3]
+	want, wantErr := DecodeWithConfig(truncated, cfg)
+	if _, err := DecodeLZ77To(&out, truncated, cfg); err == nil || wantErr == nil || out.String() != want.Output {
+		t.Errorf("truncated stream: error %v, wrote %d characters, want %v after %d", err, out.Len(), wantErr, len(want.Output))
+	}
+
+	// A write error stops the decode and is returned as is
+	writeErr := errors.New("disk full")
+	if _, err := DecodeLZ77To(failingWriter{writeErr}, bitStream, cfg); err != writeErr {
+		t.Errorf("error = %v, want the write error", err)
+	}
+}
+
+// failingWriter is an io.Writer that always fails with err.
+type failingWriter struct{ err error }
+
+func (w failingWriter) Write(p []byte) (int, error) { return 0, w.err }
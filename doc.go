@@ -0,0 +1,13 @@
+// Package voynich provides tools for testing compression hypotheses against
+// the Voynich Manuscript.
+//
+// The package decodes candidate bitstreams with an LZ77-like algorithm and
+// scores the results with information-theoretic metrics such as Shannon
+// entropy. Lower entropy may indicate more structured, language-like output,
+// which makes it a simple fitness function when exploring the decoder's
+// parameter space.
+//
+// A typical analysis builds a bitstream (for example with GenerateBitStream),
+// decodes it under several offset/length field widths with DecodeLZ77, and
+// compares the ShannonEntropy of each result.
+package voynich
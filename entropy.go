@@ -0,0 +1,509 @@
+package voynich
+
+import (
+	"math"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ShannonEntropy computes the Shannon entropy in bits/symbol for a given string.
+// Lower entropy may indicate more structured data (e.g., natural language).
+func ShannonEntropy(data string) float64 {
+	return EntropyBase(data, 2)
+}
+
+// EntropyBase computes the Shannon entropy of a string using logarithms of
+// the given base: 2 for bits, math.E for nats, 10 for hartleys. A base of 1
+// or less has no meaningful logarithm, so EntropyBase returns NaN for it.
+func EntropyBase(data string, base float64) float64 {
+	if base <= 1 {
+		return math.NaN()
+	}
+	return EntropyFromCounts(CharCounts(data)) / math.Log2(base)
+}
+
+// EntropyIgnoringWhitespace computes the Shannon entropy in bits/symbol of
+// a string with whitespace left out of the histogram. Spaces are usually
+// the most common symbol in running text and dominate the distribution;
+// the letter-only figure is the one usually quoted in cross-language
+// comparisons. Text that is all whitespace returns 0.
+func EntropyIgnoringWhitespace(data string) float64 {
+	counts := CharCounts(data)
+	for char := range counts {
+		if unicode.IsSpace(char) {
+			delete(counts, char)
+		}
+	}
+	return EntropyFromCounts(counts)
+}
+
+// EntropyMode selects the unit of symbol counting for EntropyWithMode.
+type EntropyMode int
+
+const (
+	// RuneMode counts Unicode code points, as ShannonEntropy does.
+	RuneMode EntropyMode = iota
+	// ByteMode counts raw bytes, so a multi-byte UTF-8 character
+	// contributes one symbol per byte. This matches byte-oriented
+	// compressors.
+	ByteMode
+)
+
+// EntropyWithMode computes the Shannon entropy in bits/symbol of a string,
+// counting symbols as runes or bytes according to mode.
+func EntropyWithMode(data string, mode EntropyMode) float64 {
+	if mode != ByteMode {
+		return ShannonEntropy(data)
+	}
+
+	byteCounts := make(map[byte]int)
+	for i := 0; i < len(data); i++ {
+		byteCounts[data[i]]++
+	}
+	return entropyOfCounts(byteCounts, len(data))
+}
+
+// CharCounts returns the number of occurrences of each character in a
+// string. Pass the result to EntropyFromCounts to reuse one histogram for
+// several statistics. Inputs of parallelCountThreshold bytes or more are
+// counted in chunks across goroutines; the result is the same either way.
+func CharCounts(data string) map[rune]int {
+	workers := runtime.NumCPU()
+	if len(data) < parallelCountThreshold || workers < 2 {
+		return countRunes(data)
+	}
+	return countRunesParallel(data, workers)
+}
+
+// parallelCountThreshold is the input size in bytes from which CharCounts
+// splits the histogram build across goroutines. Below it the cost of
+// starting workers and merging their maps outweighs the gain.
+const parallelCountThreshold = 1 << 20
+
+// countRunes builds the character histogram of data serially.
+func countRunes(data string) map[rune]int {
+	charCounts := make(map[rune]int)
+	for _, char := range data {
+		charCounts[char]++
+	}
+	return charCounts
+}
+
+// countRunesParallel splits data into one chunk per worker, counts each
+// chunk in its own goroutine and merges the partial histograms. Chunk
+// boundaries are moved forward to the next rune start so no multi-byte
+// character is split between two chunks.
+func countRunesParallel(data string, workers int) map[rune]int {
+	chunkSize := (len(data) + workers - 1) / workers
+	partials := make([]map[rune]int, workers)
+
+	var wg sync.WaitGroup
+	start := 0
+	for i := 0; i < workers && start < len(data); i++ {
+		end := start + chunkSize
+		if end >= len(data) {
+			end = len(data)
+		} else {
+			for end < len(data) && !utf8.RuneStart(data[end]) {
+				end++
+			}
+		}
+
+		wg.Add(1)
+		go func(i int, chunk string) {
+			defer wg.Done()
+			partials[i] = countRunes(chunk)
+		}(i, data[start:end])
+		start = end
+	}
+	wg.Wait()
+
+	// Merge the per-chunk histograms
+	charCounts := make(map[rune]int)
+	for _, partial := range partials {
+		for char, count := range partial {
+			charCounts[char] += count
+		}
+	}
+	return charCounts
+}
+
+// EntropyFromCounts computes the Shannon entropy in bits/symbol of a
+// precomputed character histogram, as returned by CharCounts.
+func EntropyFromCounts(counts map[rune]int) float64 {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	// Calculate entropy using formula: H = -Σ p(x_i) * log2(p(x_i))
+	return entropyOfCounts(counts, total)
+}
+
+// EntropyAccumulator maintains the character histogram of a changing text
+// so its Shannon entropy can be read after each change without recounting:
+// Add and Remove cost O(1) and Value is proportional to the alphabet size,
+// not the text length. It suits sliding windows and decodes that grow one
+// symbol at a time. The zero value is an empty accumulator.
+type EntropyAccumulator struct {
+	counts map[rune]int
+	total  int
+}
+
+// Add counts one occurrence of r.
+func (a *EntropyAccumulator) Add(r rune) {
+	if a.counts == nil {
+		a.counts = make(map[rune]int)
+	}
+	a.counts[r]++
+	a.total++
+}
+
+// Remove uncounts one occurrence of r, as when it leaves a sliding window.
+// Removing a character that is not counted does nothing.
+func (a *EntropyAccumulator) Remove(r rune) {
+	count := a.counts[r]
+	if count == 0 {
+		return
+	}
+	if count == 1 {
+		delete(a.counts, r)
+	} else {
+		a.counts[r] = count - 1
+	}
+	a.total--
+}
+
+// Len returns the number of characters currently counted.
+func (a *EntropyAccumulator) Len() int {
+	return a.total
+}
+
+// Value returns the Shannon entropy in bits/symbol of the counted
+// characters. It equals ShannonEntropy of a string holding them.
+func (a *EntropyAccumulator) Value() float64 {
+	return entropyOfCounts(a.counts, a.total)
+}
+
+// ConditionalEntropy computes H(X_n | X_{n-1}) in bits/symbol from the
+// empirical bigram distribution of a string. Unlike ShannonEntropy it is
+// sensitive to symbol order, so it separates structured text from a shuffle
+// of the same characters. Empty and single-character input returns 0.
+func ConditionalEntropy(data string) float64 {
+	return EntropyRate(data, 1)
+}
+
+// EntropyRate estimates the entropy rate H(X_n | X_{n-1}...X_{n-order}) in
+// bits/symbol using an order-k Markov model built from the empirical
+// frequencies of each context and the symbol that follows it. Order 0 is
+// plain ShannonEntropy. An order too large for the data is reduced to the
+// highest computable order, len(data)-1.
+func EntropyRate(data string, order int) float64 {
+	symbols := []rune(data)
+	if len(symbols) == 0 {
+		return 0
+	}
+	if order < 0 {
+		order = 0
+	}
+	if order > len(symbols)-1 {
+		order = len(symbols) - 1
+	}
+
+	// Count each (context, next symbol) pair and each context on its own
+	type transition struct {
+		context string
+		next    rune
+	}
+	transitionCounts := make(map[transition]int)
+	contextCounts := make(map[string]int)
+	for i := order; i < len(symbols); i++ {
+		context := string(symbols[i-order : i])
+		transitionCounts[transition{context, symbols[i]}]++
+		contextCounts[context]++
+	}
+
+	var entropy float64
+	totalTransitions := float64(len(symbols) - order)
+
+	// H = -Σ p(context, x) * log2(p(x | context))
+	for t, count := range transitionCounts {
+		joint := float64(count) / totalTransitions
+		conditional := float64(count) / float64(contextCounts[t.context])
+		entropy -= joint * math.Log2(conditional)
+	}
+
+	return entropy
+}
+
+// BlockEntropy computes the Shannon entropy in bits of the distribution of
+// overlapping n-character blocks in a string. Divide the result by n for a
+// per-symbol figure. For n == 1 it equals ShannonEntropy. It returns 0 when
+// n < 1 or the string is shorter than n characters.
+func BlockEntropy(data string, n int) float64 {
+	symbols := []rune(data)
+	if n < 1 || len(symbols) < n {
+		return 0
+	}
+
+	// Slide a window of n symbols across the string and count each block
+	blockCounts := make(map[string]int)
+	for i := 0; i+n <= len(symbols); i++ {
+		blockCounts[string(symbols[i:i+n])]++
+	}
+
+	return entropyOfCounts(blockCounts, len(symbols)-n+1)
+}
+
+// entropyOfCounts computes -Σ p log2(p) for a frequency table whose counts
+// sum to total. Counts are summed in sorted order rather than map order so
+// the result is bit-for-bit reproducible across calls.
+func entropyOfCounts[K comparable](counts map[K]int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	sorted := make([]int, 0, len(counts))
+	for _, count := range counts {
+		if count > 0 {
+			sorted = append(sorted, count)
+		}
+	}
+	sort.Ints(sorted)
+
+	var entropy float64
+	for _, count := range sorted {
+		probability := float64(count) / float64(total)
+		entropy -= probability * math.Log2(probability)
+	}
+	return entropy
+}
+
+// DefaultSmoothing is the additive smoothing constant used by KLDivergence.
+const DefaultSmoothing = 0.5
+
+// KLDivergence computes the Kullback-Leibler divergence D(P‖Q) in bits
+// between the character distributions of p and q, using DefaultSmoothing so
+// that characters of p missing from q do not produce an infinite result.
+func KLDivergence(p, q string) float64 {
+	return KLDivergenceSmoothed(p, q, DefaultSmoothing)
+}
+
+// KLDivergenceSmoothed computes D(P‖Q) = Σ p(x) log2(p(x)/q(x)) with additive
+// smoothing: alpha is added to the count of every character seen in either
+// string before both distributions are normalized. Smoothing both sides
+// keeps the divergence of a text from itself at exactly 0. With alpha == 0
+// the result is +Inf when p contains a character that q lacks.
+func KLDivergenceSmoothed(p, q string, alpha float64) float64 {
+	if len(p) == 0 {
+		return 0
+	}
+	if alpha < 0 {
+		alpha = 0
+	}
+
+	pCounts := CharCounts(p)
+	qCounts := CharCounts(q)
+
+	// Build the union alphabet so both distributions share one support
+	alphabet := make(map[rune]bool)
+	for char := range pCounts {
+		alphabet[char] = true
+	}
+	for char := range qCounts {
+		alphabet[char] = true
+	}
+
+	pTotal := float64(len([]rune(p))) + alpha*float64(len(alphabet))
+	qTotal := float64(len([]rune(q))) + alpha*float64(len(alphabet))
+	if qTotal == 0 {
+		return math.Inf(1) // Unsmoothed empty q lacks every character of p
+	}
+
+	var divergence float64
+	for char := range alphabet {
+		pProb := (float64(pCounts[char]) + alpha) / pTotal
+		if pProb == 0 {
+			continue // 0 * log(0/q) contributes nothing
+		}
+		qProb := (float64(qCounts[char]) + alpha) / qTotal
+		if qProb == 0 {
+			return math.Inf(1)
+		}
+		divergence += pProb * math.Log2(pProb/qProb)
+	}
+
+	return divergence
+}
+
+// PartialWindows selects how EntropyProfileWithMode treats windows that run
+// past the end of the text.
+type PartialWindows int
+
+const (
+	// SkipPartialWindows drops windows that would run past the end.
+	SkipPartialWindows PartialWindows = iota
+	// ShrinkPartialWindows truncates such windows at the end of the text,
+	// so every start position up to the end gets a value.
+	ShrinkPartialWindows
+)
+
+// EntropyProfile slides a window of windowSize characters across a string
+// in steps of step characters and returns the Shannon entropy of each
+// window, showing where structure appears along a long decode. Windows
+// that would run past the end are skipped. It returns nil when windowSize
+// or step is less than 1.
+func EntropyProfile(data string, windowSize, step int) []float64 {
+	return EntropyProfileWithMode(data, windowSize, step, SkipPartialWindows)
+}
+
+// EntropyProfileWithMode is EntropyProfile with control over windows that
+// run past the end of the text.
+func EntropyProfileWithMode(data string, windowSize, step int, mode PartialWindows) []float64 {
+	if windowSize < 1 || step < 1 {
+		return nil
+	}
+
+	symbols := []rune(data)
+	profile := []float64{}
+	for start := 0; start < len(symbols); start += step {
+		end := start + windowSize
+		if end > len(symbols) {
+			if mode == SkipPartialWindows {
+				break
+			}
+			end = len(symbols)
+		}
+		profile = append(profile, ShannonEntropy(string(symbols[start:end])))
+	}
+	return profile
+}
+
+// PerLineEntropy splits a string into lines and returns the Shannon entropy
+// of each, following the manuscript's own structural unit to expose
+// line-initial and line-final effects. Lines end at "\n" with an optional
+// preceding "\r", and a final line terminator does not start another line.
+// Empty lines give 0, and empty input returns an empty slice.
+func PerLineEntropy(data string) []float64 {
+	lines := strings.Split(strings.TrimSuffix(data, "\n"), "\n")
+	if data == "" {
+		lines = nil
+	}
+
+	profile := make([]float64, len(lines))
+	for i, line := range lines {
+		profile[i] = ShannonEntropy(strings.TrimSuffix(line, "\r"))
+	}
+	return profile
+}
+
+// MaxEntropy returns the largest entropy possible for the observed
+// alphabet, log2 of the number of distinct characters in data: the entropy
+// the text would have if every character were equally likely.
+func MaxEntropy(data string) float64 {
+	distinct := len(CharCounts(data))
+	if distinct == 0 {
+		return 0
+	}
+	return math.Log2(float64(distinct))
+}
+
+// Perplexity returns 2^H of the character distribution of data: the number
+// of equally likely characters that would give the same Shannon entropy.
+// It reads more intuitively than bits, as an effective alphabet size: a
+// text over 26 equally common letters has perplexity 26. Empty input has
+// perplexity 1.
+func Perplexity(data string) float64 {
+	return math.Exp2(ShannonEntropy(data))
+}
+
+// Redundancy returns 1 - H/Hmax, the fraction of the maximum entropy the
+// text does not use. It ranks how structured a decode is independently of
+// its alphabet size. Text with fewer than two distinct characters, for
+// which Hmax is 0, has redundancy 0.
+func Redundancy(data string) float64 {
+	counts := CharCounts(data)
+	if len(counts) < 2 {
+		return 0
+	}
+	return 1 - EntropyFromCounts(counts)/math.Log2(float64(len(counts)))
+}
+
+// RenyiEntropy computes the Rényi entropy of order alpha in bits,
+// H_α = log2(Σ p^α) / (1-α). Order 1 is the Shannon entropy, order 2 the
+// collision entropy, and order +Inf the min-entropy -log2(max p); higher
+// orders are more sensitive to the most common symbols and lower orders to
+// rare ones. A negative alpha returns NaN.
+func RenyiEntropy(data string, alpha float64) float64 {
+	if alpha < 0 || math.IsNaN(alpha) {
+		return math.NaN()
+	}
+
+	counts := CharCounts(data)
+	total := 0
+	maxCount := 0
+	for _, count := range counts {
+		total += count
+		if count > maxCount {
+			maxCount = count
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+
+	switch {
+	case alpha == 1:
+		return EntropyFromCounts(counts)
+	case math.IsInf(alpha, 1):
+		return -math.Log2(float64(maxCount) / float64(total))
+	}
+
+	// Sum in sorted order so the result is reproducible, as entropyOfCounts does
+	sorted := make([]int, 0, len(counts))
+	for _, count := range counts {
+		sorted = append(sorted, count)
+	}
+	sort.Ints(sorted)
+
+	var sum float64
+	for _, count := range sorted {
+		sum += math.Pow(float64(count)/float64(total), alpha)
+	}
+	return math.Log2(sum) / (1 - alpha)
+}
+
+// MinEntropy computes -log2 of the probability of the most frequent
+// character, the worst-case predictability of the text. It is the Rényi
+// entropy of order +Inf and never exceeds the Shannon entropy.
+func MinEntropy(data string) float64 {
+	return RenyiEntropy(data, math.Inf(1))
+}
+
+// GuessingEntropy computes the expected number of guesses needed to
+// identify a character drawn from the text when candidates are tried from
+// most to least frequent: Σ i * p_(i) with probabilities in descending
+// order. Empty input returns 0.
+func GuessingEntropy(data string) float64 {
+	counts := CharCounts(data)
+	sorted := make([]int, 0, len(counts))
+	total := 0
+	for _, count := range counts {
+		sorted = append(sorted, count)
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+
+	var guesses float64
+	for i, count := range sorted {
+		guesses += float64(i+1) * float64(count) / float64(total)
+	}
+	return guesses
+}
@@ -0,0 +1,441 @@
+package voynich
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestKLDivergenceSmoothedEmptyQ(t *testing.T) {
+	if got := KLDivergenceSmoothed("abc", "", 0); !math.IsInf(got, 1) {
+		t.Errorf("KLDivergenceSmoothed(\"abc\", \"\", 0) = %v, want +Inf", got)
+	}
+	if got := KLDivergenceSmoothed("", "", 0); got != 0 {
+		t.Errorf("KLDivergenceSmoothed(\"\", \"\", 0) = %v, want 0", got)
+	}
+	if got := KLDivergenceSmoothed("abc", "", 1); math.IsNaN(got) || math.IsInf(got, 0) {
+		t.Errorf("KLDivergenceSmoothed(\"abc\", \"\", 1) = %v, want a finite value", got)
+	}
+}
+
+func TestCountRunesParallelMatchesSerial(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "empty", data: ""},
+		{name: "ascii", data: benchText(10 << 10)},
+		// Runes of one to four bytes put most chunk boundaries inside a
+		// character, so the boundary has to move forward to a rune start
+		{name: "multi-byte", data: strings.Repeat("a€ю𝄞", 1000)},
+		{name: "single rune", data: "€"},
+	}
+	for _, tt := range tests {
+		want := countRunes(tt.data)
+		for _, workers := range []int{1, 2, 3, 7, 16} {
+			t.Run(fmt.Sprintf("%s/%d", tt.name, workers), func(t *testing.T) {
+				got := countRunesParallel(tt.data, workers)
+				if !maps.Equal(got, want) {
+					t.Errorf("countRunesParallel differs from countRunes: got %v, want %v", got, want)
+				}
+				if _, ok := got[utf8.RuneError]; ok && !strings.ContainsRune(tt.data, utf8.RuneError) {
+					t.Error("a chunk boundary split a multi-byte rune")
+				}
+			})
+		}
+	}
+}
+
+func TestKLDivergence(t *testing.T) {
+	text := "the quick brown fox jumps over the lazy dog"
+	if got := KLDivergence(text, text); got != 0 {
+		t.Errorf("KLDivergence of a text from itself = %v, want 0", got)
+	}
+	if got := KLDivergenceSmoothed(text, text, 0); got != 0 {
+		t.Errorf("unsmoothed KLDivergence of a text from itself = %v, want 0", got)
+	}
+
+	// 'c' is missing from q: infinite without smoothing, finite with it
+	if got := KLDivergenceSmoothed("abc", "ab", 0); !math.IsInf(got, 1) {
+		t.Errorf("KLDivergenceSmoothed(\"abc\", \"ab\", 0) = %v, want +Inf", got)
+	}
+	got := KLDivergence("abc", "ab")
+	if math.IsInf(got, 0) || got <= 0 {
+		t.Errorf("KLDivergence(\"abc\", \"ab\") = %v, want a positive finite value", got)
+	}
+
+	// p = (1/2, 1/2) against q = (1/4, 3/4)
+	want := 0.5*math.Log2(2) + 0.5*math.Log2(2.0/3)
+	if got := KLDivergenceSmoothed("ab", "abbb", 0); math.Abs(got-want) > 1e-12 {
+		t.Errorf("KLDivergenceSmoothed(\"ab\", \"abbb\", 0) = %v, want %v", got, want)
+	}
+}
+
+func TestConditionalEntropy(t *testing.T) {
+	// Each character determines the next, so nothing is left to predict
+	structured := strings.Repeat("abcd", 50)
+	if got := ConditionalEntropy(structured); got > 1e-12 {
+		t.Errorf("ConditionalEntropy(structured) = %v, want 0", got)
+	}
+	// The same characters in random order keep their unigram entropy of 2
+	// bits but are no longer predictable from the previous character
+	shuffled := []byte(structured)
+	rand.New(rand.NewSource(1)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	if got := ConditionalEntropy(string(shuffled)); got < 1.5 {
+		t.Errorf("ConditionalEntropy(shuffled) = %v, want close to 2", got)
+	}
+
+	for _, data := range []string{"", "a"} {
+		if got := ConditionalEntropy(data); got != 0 {
+			t.Errorf("ConditionalEntropy(%q) = %v, want 0", data, got)
+		}
+	}
+}
+
+func TestBlockEntropy(t *testing.T) {
+	text := "the quick brown fox jumps over the lazy dog"
+	if got, want := BlockEntropy(text, 1), ShannonEntropy(text); got != want {
+		t.Errorf("BlockEntropy(n=1) = %v, want ShannonEntropy %v", got, want)
+	}
+
+	// A period-4 text has only four distinct blocks of any length, while
+	// random bytes over the same alphabet have nearly all blocks distinct
+	repetitive := strings.Repeat("abcd", 100)
+	source := rand.New(rand.NewSource(1))
+	random := make([]byte, len(repetitive))
+	for i := range random {
+		random[i] = "abcd"[source.Intn(4)]
+	}
+	for _, n := range []int{2, 3, 4} {
+		if got := BlockEntropy(repetitive, n); math.Abs(got-2) > 1e-3 {
+			t.Errorf("BlockEntropy(repetitive, %d) = %v, want about 2", n, got)
+		}
+		if got := BlockEntropy(string(random), n); got < 2*float64(n)-1 {
+			t.Errorf("BlockEntropy(random, %d) = %v, want close to %d", n, got, 2*n)
+		}
+	}
+
+	if got := BlockEntropy("ab", 3); got != 0 {
+		t.Errorf("BlockEntropy with n past the text = %v, want 0", got)
+	}
+	if got := BlockEntropy("ab", 0); got != 0 {
+		t.Errorf("BlockEntropy(n=0) = %v, want 0", got)
+	}
+}
+
+func TestEntropyRate(t *testing.T) {
+	text := benchText(8 << 10)
+	previous := EntropyRate(text, 0)
+	if math.Abs(previous-ShannonEntropy(text)) > 1e-12 {
+		t.Errorf("EntropyRate(order 0) = %v, want ShannonEntropy %v", previous, ShannonEntropy(text))
+	}
+	for order := 1; order <= 4; order++ {
+		rate := EntropyRate(text, order)
+		if rate > previous {
+			t.Errorf("EntropyRate(order %d) = %v, above order %d's %v", order, rate, order-1, previous)
+		}
+		previous = rate
+	}
+
+	// Orders past the text are reduced to len-1, and negative orders to 0
+	if got, want := EntropyRate("abcab", 10), EntropyRate("abcab", 4); math.Abs(got-want) > 1e-12 {
+		t.Errorf("EntropyRate(order 10) = %v, want order 4's %v", got, want)
+	}
+	if got, want := EntropyRate("abcab", -1), ShannonEntropy("abcab"); math.Abs(got-want) > 1e-12 {
+		t.Errorf("EntropyRate(order -1) = %v, want %v", got, want)
+	}
+	if got := EntropyRate("", 2); got != 0 {
+		t.Errorf("EntropyRate(\"\") = %v, want 0", got)
+	}
+}
+
+func TestEntropyBase(t *testing.T) {
+	text := "the quick brown fox jumps over the lazy dog"
+	bits := ShannonEntropy(text)
+	if got, want := EntropyBase(text, math.E), bits*math.Ln2; math.Abs(got-want) > 1e-12 {
+		t.Errorf("EntropyBase(e) = %v, want %v", got, want)
+	}
+	if got, want := EntropyBase(text, 10), bits*math.Log10(2); math.Abs(got-want) > 1e-12 {
+		t.Errorf("EntropyBase(10) = %v, want %v", got, want)
+	}
+	for _, base := range []float64{1, 0.5, 0, -2} {
+		if got := EntropyBase(text, base); !math.IsNaN(got) {
+			t.Errorf("EntropyBase(%v) = %v, want NaN", base, got)
+		}
+	}
+}
+
+func TestEntropyIgnoringWhitespace(t *testing.T) {
+	tests := []struct {
+		data string
+		want float64
+	}{
+		{"abcd", 2},
+		{"ab cd\tab\ncd", 2}, // Same letters, whitespace dropped
+		{"aaaa", 0},
+		{" \t\n\r", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := EntropyIgnoringWhitespace(tt.data); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("EntropyIgnoringWhitespace(%q) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+
+	// Text without whitespace gives the plain Shannon entropy
+	text := "thequickbrownfoxjumpsoverthelazydog"
+	if got, want := EntropyIgnoringWhitespace(text), ShannonEntropy(text); got != want {
+		t.Errorf("no whitespace: got %v, want %v", got, want)
+	}
+}
+
+func TestEntropyFromCounts(t *testing.T) {
+	for _, data := range []string{"", "a", "hello, world", "ünïcödé text", benchText(4 << 10)} {
+		if got, want := EntropyFromCounts(CharCounts(data)), ShannonEntropy(data); got != want {
+			t.Errorf("EntropyFromCounts(CharCounts(%.20q)) = %v, want %v", data, got, want)
+		}
+	}
+	counts := CharCounts("abracadabra")
+	want := map[rune]int{'a': 5, 'b': 2, 'r': 2, 'c': 1, 'd': 1}
+	if !maps.Equal(counts, want) {
+		t.Errorf("CharCounts = %v, want %v", counts, want)
+	}
+}
+
+func TestEntropyProfile(t *testing.T) {
+	// A repetitive region followed by a random one over 16 symbols
+	source := rand.New(rand.NewSource(1))
+	random := make([]byte, 400)
+	for i := range random {
+		random[i] = "abcdefghijklmnop"[source.Intn(16)]
+	}
+	data := strings.Repeat("ab", 200) + string(random)
+
+	profile := EntropyProfile(data, 100, 100)
+	if len(profile) != 8 {
+		t.Fatalf("got %d windows, want 8", len(profile))
+	}
+	for i, value := range profile {
+		repetitive := i < 4
+		if repetitive && value != 1 {
+			t.Errorf("window %d = %v, want 1 in the repetitive region", i, value)
+		}
+		if !repetitive && value < 3.5 {
+			t.Errorf("window %d = %v, want close to 4 in the random region", i, value)
+		}
+	}
+}
+
+func TestEntropyProfilePartialWindows(t *testing.T) {
+	data := "aaaabbbbab" // 10 characters
+	skip := EntropyProfileWithMode(data, 4, 3, SkipPartialWindows)
+	shrink := EntropyProfileWithMode(data, 4, 3, ShrinkPartialWindows)
+
+	// Windows start at 0, 3 and 6; the one at 9 runs past the end
+	want := []float64{0, ShannonEntropy("abbb"), ShannonEntropy("bbab")}
+	if !reflect.DeepEqual(skip, want) {
+		t.Errorf("skip: got %v, want %v", skip, want)
+	}
+	if want := append(want, 0); !reflect.DeepEqual(shrink, want) {
+		t.Errorf("shrink: got %v, want %v", shrink, want)
+	}
+	if got := EntropyProfile(data, 4, 3); !reflect.DeepEqual(got, skip) {
+		t.Errorf("EntropyProfile = %v, want the skipping profile %v", got, skip)
+	}
+
+	if got := EntropyProfile("abc", 5, 1); len(got) != 0 {
+		t.Errorf("window longer than the text: got %v, want none", got)
+	}
+	if got := EntropyProfile("abc", 0, 1); got != nil {
+		t.Errorf("windowSize 0: got %v, want nil", got)
+	}
+}
+
+func TestPerLineEntropy(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []float64
+	}{
+		{"lines", "abcd\naa\nab", []float64{2, 0, 1}},
+		{"final newline", "abcd\nab\n", []float64{2, 1}},
+		{"empty lines", "ab\n\n\nabcd", []float64{1, 0, 0, 2}},
+		{"crlf", "abcd\r\nab\r\n", []float64{2, 1}}, // "\r" is not counted
+		{"only a newline", "\n", []float64{0}},
+		{"empty", "", []float64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PerLineEntropy(tt.data)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PerLineEntropy(%q) = %v, want %v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntropyWithMode(t *testing.T) {
+	// "éé" is one symbol repeated as runes, but its two UTF-8 bytes differ
+	if got := EntropyWithMode("éé", RuneMode); got != 0 {
+		t.Errorf("RuneMode = %v, want 0", got)
+	}
+	if got := EntropyWithMode("éé", ByteMode); got != 1 {
+		t.Errorf("ByteMode = %v, want 1", got)
+	}
+
+	// The modes agree on ASCII
+	text := "the quick brown fox"
+	if EntropyWithMode(text, ByteMode) != EntropyWithMode(text, RuneMode) {
+		t.Errorf("modes differ on ASCII: %v and %v", EntropyWithMode(text, ByteMode), EntropyWithMode(text, RuneMode))
+	}
+	if got := EntropyWithMode("", ByteMode); got != 0 {
+		t.Errorf("ByteMode of empty input = %v, want 0", got)
+	}
+}
+
+func TestMaxEntropyAndRedundancy(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           string
+		max, redundant float64
+	}{
+		{name: "empty", data: "", max: 0, redundant: 0},
+		{name: "single symbol", data: "aaaa", max: 0, redundant: 0},
+		{name: "uniform", data: "abcdabcd", max: 2, redundant: 0},
+		// Probabilities 3/4 and 1/4 over a two-symbol alphabet
+		{name: "skewed", data: "aaab", max: 1, redundant: 1 - (0.75*math.Log2(4.0/3) + 0.25*2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MaxEntropy(tt.data); math.Abs(got-tt.max) > 1e-12 {
+				t.Errorf("MaxEntropy = %v, want %v", got, tt.max)
+			}
+			if got := Redundancy(tt.data); math.Abs(got-tt.redundant) > 1e-12 {
+				t.Errorf("Redundancy = %v, want %v", got, tt.redundant)
+			}
+		})
+	}
+}
+
+func TestPerplexity(t *testing.T) {
+	// A uniform alphabet of k characters has perplexity k
+	for _, alphabet := range []string{"a", "ab", "abcd", "abcdefghijklmnopqrstuvwxyz"} {
+		text := strings.Repeat(alphabet, 5)
+		if got, want := Perplexity(text), float64(len(alphabet)); math.Abs(got-want) > 1e-9 {
+			t.Errorf("Perplexity(%q repeated) = %v, want %v", alphabet, got, want)
+		}
+	}
+
+	// A skewed distribution acts like fewer equally likely characters
+	if got := Perplexity("aaaaaaab"); got <= 1 || got >= 2 {
+		t.Errorf("Perplexity(\"aaaaaaab\") = %v, want between 1 and 2", got)
+	}
+	if got := Perplexity(""); got != 1 {
+		t.Errorf("Perplexity(\"\") = %v, want 1", got)
+	}
+}
+
+func TestRenyiEntropy(t *testing.T) {
+	data := "aaaabbc" // Probabilities 4/7, 2/7 and 1/7
+	p := []float64{4.0 / 7, 2.0 / 7, 1.0 / 7}
+
+	var sumSquares float64
+	for _, probability := range p {
+		sumSquares += probability * probability
+	}
+	if got, want := RenyiEntropy(data, 2), -math.Log2(sumSquares); math.Abs(got-want) > 1e-12 {
+		t.Errorf("RenyiEntropy(α=2) = %v, want %v", got, want)
+	}
+	if got, want := RenyiEntropy(data, 1), ShannonEntropy(data); got != want {
+		t.Errorf("RenyiEntropy(α=1) = %v, want ShannonEntropy %v", got, want)
+	}
+	if got, want := RenyiEntropy(data, math.Inf(1)), -math.Log2(4.0/7); got != want {
+		t.Errorf("RenyiEntropy(α=+Inf) = %v, want %v", got, want)
+	}
+	// Order 0 is the Hartley entropy, log2 of the alphabet size
+	if got := RenyiEntropy(data, 0); math.Abs(got-math.Log2(3)) > 1e-12 {
+		t.Errorf("RenyiEntropy(α=0) = %v, want %v", got, math.Log2(3))
+	}
+
+	// The entropy does not increase with the order
+	previous := math.Inf(1)
+	for _, alpha := range []float64{0, 0.5, 1, 2, 3, math.Inf(1)} {
+		value := RenyiEntropy(data, alpha)
+		if value > previous+1e-12 {
+			t.Errorf("RenyiEntropy(α=%v) = %v, above the lower order's %v", alpha, value, previous)
+		}
+		previous = value
+	}
+
+	if got := RenyiEntropy(data, -1); !math.IsNaN(got) {
+		t.Errorf("RenyiEntropy(α=-1) = %v, want NaN", got)
+	}
+	if got := RenyiEntropy("", 2); got != 0 {
+		t.Errorf("RenyiEntropy of empty input = %v, want 0", got)
+	}
+}
+
+func TestMinAndGuessingEntropy(t *testing.T) {
+	// 'a' dominates with probability 8/10; 'b' and 'c' share the rest
+	data := "aaaaaaaabc"
+	if got, want := MinEntropy(data), -math.Log2(0.8); got != want {
+		t.Errorf("MinEntropy = %v, want %v", got, want)
+	}
+	if MinEntropy(data) > ShannonEntropy(data) {
+		t.Errorf("MinEntropy %v exceeds ShannonEntropy %v", MinEntropy(data), ShannonEntropy(data))
+	}
+	// 1*0.8 + 2*0.1 + 3*0.1
+	if got := GuessingEntropy(data); math.Abs(got-1.3) > 1e-12 {
+		t.Errorf("GuessingEntropy = %v, want 1.3", got)
+	}
+
+	// Four equally likely symbols take 2.5 guesses on average
+	if got := GuessingEntropy("abcd"); math.Abs(got-2.5) > 1e-12 {
+		t.Errorf("GuessingEntropy(uniform) = %v, want 2.5", got)
+	}
+	if got := GuessingEntropy(""); got != 0 {
+		t.Errorf("GuessingEntropy(\"\") = %v, want 0", got)
+	}
+	if got := MinEntropy("aaa"); got != 0 {
+		t.Errorf("MinEntropy of one repeated symbol = %v, want 0", got)
+	}
+}
+
+func TestEntropyAccumulator(t *testing.T) {
+	text := []rune("qokeedy daiin chol daiin €")
+	var acc EntropyAccumulator
+	if acc.Value() != 0 || acc.Len() != 0 {
+		t.Errorf("zero value: Value %v, Len %d, want 0 and 0", acc.Value(), acc.Len())
+	}
+
+	// Each Add matches a batch computation of the prefix so far
+	for i, r := range text {
+		acc.Add(r)
+		if got, want := acc.Value(), ShannonEntropy(string(text[:i+1])); got != want {
+			t.Errorf("after %d adds: Value %v, want %v", i+1, got, want)
+		}
+	}
+
+	// Removing from the front leaves a sliding window matching its suffix
+	for i, r := range text {
+		acc.Remove(r)
+		if got, want := acc.Value(), ShannonEntropy(string(text[i+1:])); got != want {
+			t.Errorf("after %d removes: Value %v, want %v", i+1, got, want)
+		}
+		if acc.Len() != len(text)-i-1 {
+			t.Errorf("after %d removes: Len %d, want %d", i+1, acc.Len(), len(text)-i-1)
+		}
+	}
+
+	acc.Remove('z') // Not counted; ignored
+	if acc.Len() != 0 || acc.Value() != 0 {
+		t.Errorf("after removing an uncounted rune: Len %d, Value %v, want 0 and 0", acc.Len(), acc.Value())
+	}
+}
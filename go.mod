@@ -1,4 +1,4 @@
-module voynich-decompressor
+module github.com/djabbat/voynich
 
 go 1.21
 
@@ -0,0 +1,107 @@
+package voynich
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// goldenDecode is what a golden file records about one decode. The fields
+// are listed explicitly so that a new DecodeResult field does not change
+// every golden file.
+type goldenDecode struct {
+	Output           string
+	SkippedRefs      int
+	LiteralCount     int
+	FilteredLiterals int
+	LiteralBytes     int
+	ReferenceBytes   int
+	ReferenceCount   int
+	Corrections      int
+	StoppedAtEOS     bool
+	EOSPosition      int
+	Error            string `json:",omitempty"`
+}
+
+// newGoldenDecode records the result and error of a decode.
+func newGoldenDecode(result DecodeResult, err error) goldenDecode {
+	decoded := goldenDecode{
+		Output:           result.Output,
+		SkippedRefs:      result.SkippedRefs,
+		LiteralCount:     result.LiteralCount,
+		FilteredLiterals: result.FilteredLiterals,
+		LiteralBytes:     result.LiteralBytes,
+		ReferenceBytes:   result.ReferenceBytes,
+		ReferenceCount:   result.ReferenceCount,
+		Corrections:      result.Corrections,
+		StoppedAtEOS:     result.StoppedAtEOS,
+		EOSPosition:      result.EOSPosition,
+	}
+	if err != nil {
+		decoded.Error = err.Error()
+	}
+	return decoded
+}
+
+// TestGoldenDecodes decodes every testdata/golden/<name>.bits fixture with
+// the DecoderConfig in <name>.json and compares the output, statistics and
+// error with <name>.golden. Run with -update to regenerate the golden files
+// after an intended change in behavior.
+func TestGoldenDecodes(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "golden", "*.bits"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("no fixtures in testdata/golden")
+	}
+
+	for _, fixture := range fixtures {
+		base := strings.TrimSuffix(fixture, ".bits")
+		t.Run(filepath.Base(base), func(t *testing.T) {
+			data, err := os.ReadFile(fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bitStream, err := ParseBitStream(string(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var cfg DecoderConfig
+			data, err = os.ReadFile(base + ".json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal(data, &cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := json.MarshalIndent(newGoldenDecode(DecodeWithConfig(bitStream, cfg)), "", "\t")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			golden := base + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("decode differs from %s:\ngot:\n%s\nwant:\n%s", golden, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package voynich
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// HuffmanDecoder decodes bitstreams with a fixed prefix code. It is built
+// once by NewHuffmanDecoder, which validates the code table.
+type HuffmanDecoder struct {
+	root *huffmanNode
+}
+
+// huffmanNode is a node of the binary code trie. Leaves carry a symbol.
+type huffmanNode struct {
+	children [2]*huffmanNode
+	symbol   rune
+	leaf     bool
+}
+
+// NewHuffmanDecoder builds a decoder from a table mapping each code, written
+// as '0'/'1' characters, to its symbol. The table must be a prefix code: no
+// code may be empty or a prefix of another.
+func NewHuffmanDecoder(codes map[string]rune) (*HuffmanDecoder, error) {
+	if len(codes) == 0 {
+		return nil, errors.New("huffman code table is empty")
+	}
+
+	root := &huffmanNode{}
+	for code, symbol := range codes {
+		if code == "" {
+			return nil, fmt.Errorf("empty code for symbol %q", symbol)
+		}
+
+		node := root
+		for i := 0; i < len(code); i++ {
+			if code[i] != '0' && code[i] != '1' {
+				return nil, fmt.Errorf("code %q for symbol %q contains %q", code, symbol, code[i])
+			}
+			if node.leaf {
+				return nil, fmt.Errorf("code %q has another code as a prefix", code)
+			}
+			bit := code[i] - '0'
+			if node.children[bit] == nil {
+				node.children[bit] = &huffmanNode{}
+			}
+			node = node.children[bit]
+		}
+
+		if node.leaf || node.children[0] != nil || node.children[1] != nil {
+			return nil, fmt.Errorf("code %q is a prefix of another code", code)
+		}
+		node.leaf = true
+		node.symbol = symbol
+	}
+
+	return &HuffmanDecoder{root: root}, nil
+}
+
+// Decode walks the bitstream, emitting the symbol of each code it matches.
+// Bits that match no code, or a partial code left at the end of the
+// stream, are an error; the output decoded so far is returned with it.
+func (h *HuffmanDecoder) Decode(bitStream string) (string, error) {
+	var output strings.Builder
+	node := h.root
+	codeStart := 0
+
+	for position := 0; position < len(bitStream); position++ {
+		if bitStream[position] != '0' && bitStream[position] != '1' {
+			return output.String(), fmt.Errorf("invalid bitstream character %q at position %d", bitStream[position], position)
+		}
+
+		node = node.children[bitStream[position]-'0']
+		if node == nil {
+			return output.String(), fmt.Errorf("no code matches bits %q at position %d",
+				bitStream[codeStart:position+1], codeStart)
+		}
+		if node.leaf {
+			output.WriteRune(node.symbol)
+			node = h.root
+			codeStart = position + 1
+		}
+	}
+
+	if codeStart < len(bitStream) {
+		return output.String(), fmt.Errorf("dangling bits %q at position %d", bitStream[codeStart:], codeStart)
+	}
+	return output.String(), nil
+}
+
+// DecodeHuffman decodes a bitstream with the given prefix code table. It is
+// shorthand for NewHuffmanDecoder followed by Decode.
+func DecodeHuffman(bitStream string, codes map[string]rune) (string, error) {
+	decoder, err := NewHuffmanDecoder(codes)
+	if err != nil {
+		return "", err
+	}
+	return decoder.Decode(bitStream)
+}
+
+// InvertCodes turns a symbol-to-code map, as returned by BuildHuffman, into
+// the code-to-symbol table used by NewHuffmanDecoder and DecodeHuffman.
+func InvertCodes(codes map[rune]string) map[string]rune {
+	table := make(map[string]rune, len(codes))
+	for symbol, code := range codes {
+		table[code] = symbol
+	}
+	return table
+}
+
+// BuildHuffman builds a canonical Huffman code for the given symbol
+// frequencies and returns it with the total encoded length in bits,
+// Σ freq(s) * len(code(s)). Symbols with a non-positive frequency get no
+// code, and a lone symbol gets the one-bit code "0".
+//
+// Construction is deterministic: equal weights are merged in order of the
+// smallest symbol they contain, and canonical codes are assigned in order
+// of code length and then symbol value. Use InvertCodes to build a decoder
+// table from the result.
+func BuildHuffman(freq map[rune]int) (map[rune]string, int) {
+	var symbols []rune
+	for symbol, count := range freq {
+		if count > 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i] < symbols[j] })
+
+	codes := make(map[rune]string, len(symbols))
+	if len(symbols) == 0 {
+		return codes, 0
+	}
+
+	// Build the Huffman tree to find each symbol's code length
+	lengths := make(map[rune]int, len(symbols))
+	if len(symbols) == 1 {
+		lengths[symbols[0]] = 1
+	} else {
+		queue := make(huffmanQueue, len(symbols))
+		for i, symbol := range symbols {
+			queue[i] = &huffmanTree{weight: freq[symbol], minSymbol: symbol, symbols: []rune{symbol}}
+		}
+		heap.Init(&queue)
+
+		for queue.Len() > 1 {
+			a := heap.Pop(&queue).(*huffmanTree)
+			b := heap.Pop(&queue).(*huffmanTree)
+
+			// Every symbol under the merged node moves one level deeper
+			for _, symbol := range a.symbols {
+				lengths[symbol]++
+			}
+			for _, symbol := range b.symbols {
+				lengths[symbol]++
+			}
+
+			minSymbol := a.minSymbol
+			if b.minSymbol < minSymbol {
+				minSymbol = b.minSymbol
+			}
+			heap.Push(&queue, &huffmanTree{
+				weight:    a.weight + b.weight,
+				minSymbol: minSymbol,
+				symbols:   append(a.symbols, b.symbols...),
+			})
+		}
+	}
+
+	// Assign canonical codes in order of (length, symbol)
+	sort.SliceStable(symbols, func(i, j int) bool {
+		return lengths[symbols[i]] < lengths[symbols[j]]
+	})
+	code, prevLength, totalBits := 0, lengths[symbols[0]], 0
+	for _, symbol := range symbols {
+		length := lengths[symbol]
+		code <<= uint(length - prevLength)
+		prevLength = length
+
+		var bits strings.Builder
+		writeBits(&bits, code, length)
+		codes[symbol] = bits.String()
+		totalBits += freq[symbol] * length
+		code++
+	}
+
+	return codes, totalBits
+}
+
+// huffmanTree is a subtree during Huffman construction.
+type huffmanTree struct {
+	weight    int
+	minSymbol rune   // Smallest symbol in the subtree, for tie-breaking
+	symbols   []rune // All symbols in the subtree
+}
+
+// huffmanQueue is a min-heap of subtrees ordered by weight, then minSymbol.
+type huffmanQueue []*huffmanTree
+
+func (q huffmanQueue) Len() int { return len(q) }
+func (q huffmanQueue) Less(i, j int) bool {
+	if q[i].weight != q[j].weight {
+		return q[i].weight < q[j].weight
+	}
+	return q[i].minSymbol < q[j].minSymbol
+}
+func (q huffmanQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *huffmanQueue) Push(x any)   { *q = append(*q, x.(*huffmanTree)) }
+func (q *huffmanQueue) Pop() any {
+	old := *q
+	tree := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return tree
+}
@@ -0,0 +1,131 @@
+package voynich
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestDecodeHuffman(t *testing.T) {
+	codes := map[string]rune{"0": 'e', "10": 't', "110": 'a', "111": 'o'}
+	tests := []struct {
+		name      string
+		bitStream string
+		want      string
+		wantErr   string
+	}{
+		{name: "empty", bitStream: "", want: ""},
+		{name: "every code", bitStream: "0" + "10" + "110" + "111", want: "etao"},
+		{name: "dangling bits", bitStream: "01011", want: "et", wantErr: `dangling bits "11" at position 3`},
+		{name: "bad character", bitStream: "0102", want: "et", wantErr: `invalid bitstream character '2' at position 3`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeHuffman(tt.bitStream, codes)
+			if tt.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// An incomplete code leaves bit patterns that match nothing
+	got, err := DecodeHuffman("0110", map[string]rune{"0": 'e', "10": 't'})
+	if err == nil || err.Error() != `no code matches bits "11" at position 1` || got != "e" {
+		t.Errorf("got %q, %v, want %q with no code matching \"11\"", got, err, "e")
+	}
+}
+
+func TestNewHuffmanDecoderRejectsInvalidCodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		codes   map[string]rune
+		wantErr string
+	}{
+		{name: "empty table", codes: map[string]rune{}, wantErr: "empty"},
+		{name: "empty code", codes: map[string]rune{"": 'a', "1": 'b'}, wantErr: "empty code"},
+		{name: "non-bit code", codes: map[string]rune{"0x": 'a'}, wantErr: "contains"},
+		{name: "prefix", codes: map[string]rune{"0": 'a', "01": 'b'}, wantErr: "prefix"},
+		{name: "equal codes", codes: map[string]rune{"1": 'a', "10": 'b', "11": 'c'}, wantErr: "prefix"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewHuffmanDecoder(tt.codes); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildHuffman(t *testing.T) {
+	freq := map[rune]int{'e': 40, 't': 20, 'a': 15, 'o': 15, 'i': 5, 'n': 5, 'z': 0}
+	codes, totalBits := BuildHuffman(freq)
+
+	if _, ok := codes['z']; ok || len(codes) != 6 {
+		t.Errorf("got codes for %d symbols, want 6 without 'z': %v", len(codes), codes)
+	}
+	if _, err := NewHuffmanDecoder(InvertCodes(codes)); err != nil {
+		t.Errorf("code is not prefix-free: %v", err)
+	}
+
+	// More frequent symbols never get longer codes
+	for a, countA := range freq {
+		for b, countB := range freq {
+			if countA > countB && countB > 0 && len(codes[a]) > len(codes[b]) {
+				t.Errorf("%q (count %d) has code %q, longer than %q (count %d) with %q", a, countA, codes[a], b, countB, codes[b])
+			}
+		}
+	}
+
+	want := 0
+	for symbol, count := range freq {
+		want += count * len(codes[symbol])
+	}
+	if totalBits != want {
+		t.Errorf("total bits = %d, want %d", totalBits, want)
+	}
+
+	// Map iteration order does not change the result
+	for i := 0; i < 10; i++ {
+		again, againBits := BuildHuffman(freq)
+		if !maps.Equal(again, codes) || againBits != totalBits {
+			t.Fatalf("BuildHuffman gave %v, then %v", codes, again)
+		}
+	}
+
+	if codes, bits := BuildHuffman(map[rune]int{'x': 3}); codes['x'] != "0" || bits != 3 {
+		t.Errorf("lone symbol: got %v with %d bits, want code \"0\" with 3", codes, bits)
+	}
+}
+
+func TestGenerateBitStreamCodesRoundTrip(t *testing.T) {
+	text := "this is an example of a huffman tree"
+	codes, totalBits := BuildHuffman(CharCounts(text))
+
+	bitStream, err := GenerateBitStreamCodes(text, codes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bitStream) != totalBits {
+		t.Errorf("encoded in %d bits, want BuildHuffman's %d", len(bitStream), totalBits)
+	}
+	got, err := DecodeHuffman(bitStream, InvertCodes(codes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != text {
+		t.Errorf("round trip gave %q, want %q", got, text)
+	}
+
+	if _, err := GenerateBitStreamCodes("taz", codes); err == nil || !strings.Contains(err.Error(), "position 2") {
+		t.Errorf("error = %v, want 'z' at position 2 reported", err)
+	}
+	if _, err := GenerateBitStreamCodes("a", map[rune]string{'a': "0a"}); err == nil {
+		t.Error("non-bit code accepted")
+	}
+}
@@ -0,0 +1,71 @@
+package voynich
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// LanguageFrequencies holds reference letter frequencies, in percent, for
+// the languages most often proposed for the manuscript's plaintext. The
+// tables cover the lowercase letters a–z and are approximate averages over
+// large corpora; ClosestLanguage compares decodes against them. Callers may
+// add entries before calling ClosestLanguage.
+var LanguageFrequencies = map[string]map[rune]float64{
+	"English": {
+		'a': 8.167, 'b': 1.492, 'c': 2.782, 'd': 4.253, 'e': 12.702, 'f': 2.228,
+		'g': 2.015, 'h': 6.094, 'i': 6.966, 'j': 0.153, 'k': 0.772, 'l': 4.025,
+		'm': 2.406, 'n': 6.749, 'o': 7.507, 'p': 1.929, 'q': 0.095, 'r': 5.987,
+		's': 6.327, 't': 9.056, 'u': 2.758, 'v': 0.978, 'w': 2.360, 'x': 0.150,
+		'y': 1.974, 'z': 0.074,
+	},
+	"Latin": {
+		'a': 8.89, 'b': 1.58, 'c': 3.99, 'd': 2.77, 'e': 11.38, 'f': 0.93,
+		'g': 1.21, 'h': 0.69, 'i': 11.44, 'l': 3.15, 'm': 5.38, 'n': 6.28,
+		'o': 5.40, 'p': 3.03, 'q': 1.51, 'r': 6.67, 's': 7.60, 't': 8.00,
+		'u': 8.46, 'v': 0.96, 'x': 0.60, 'y': 0.07, 'z': 0.01,
+	},
+	"Italian": {
+		'a': 11.745, 'b': 0.927, 'c': 4.501, 'd': 3.736, 'e': 11.792, 'f': 1.153,
+		'g': 1.644, 'h': 0.636, 'i': 10.143, 'j': 0.011, 'k': 0.009, 'l': 6.510,
+		'm': 2.512, 'n': 6.883, 'o': 9.832, 'p': 3.056, 'q': 0.505, 'r': 6.367,
+		's': 4.981, 't': 5.623, 'u': 3.011, 'v': 2.097, 'w': 0.033, 'x': 0.003,
+		'y': 0.020, 'z': 1.181,
+	},
+}
+
+// ClosestLanguage compares the letter frequencies of data with each table in
+// LanguageFrequencies and returns the best-matching language together with
+// its distance, the χ² statistic divided by the number of letters compared
+// so that scores are comparable across texts of different lengths. Lower
+// distances are closer. Letters are compared case-insensitively and
+// everything outside a–z is ignored. Ties go to the alphabetically first
+// language, and data with no letters returns "" and 0.
+func ClosestLanguage(data string) (string, float64) {
+	letters := strings.Map(func(r rune) rune {
+		r = unicode.ToLower(r)
+		if r < 'a' || r > 'z' {
+			return -1
+		}
+		return r
+	}, data)
+	if letters == "" {
+		return "", 0
+	}
+
+	names := make([]string, 0, len(LanguageFrequencies))
+	for name := range LanguageFrequencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	best, bestDistance := "", 0.0
+	for _, name := range names {
+		statistic, _ := ChiSquared(letters, LanguageFrequencies[name])
+		distance := statistic / float64(len(letters))
+		if best == "" || distance < bestDistance {
+			best, bestDistance = name, distance
+		}
+	}
+	return best, bestDistance
+}
@@ -0,0 +1,43 @@
+package voynich
+
+import "testing"
+
+func TestClosestLanguage(t *testing.T) {
+	tests := []struct {
+		name, text, want string
+	}{
+		{
+			name: "English",
+			text: "It was the best of times, it was the worst of times, it was the age of wisdom, " +
+				"it was the age of foolishness, it was the epoch of belief, it was the epoch of " +
+				"incredulity, it was the season of Light, it was the season of Darkness.",
+			want: "English",
+		},
+		{
+			name: "Latin",
+			text: "Gallia est omnis divisa in partes tres, quarum unam incolunt Belgae, aliam Aquitani, " +
+				"tertiam qui ipsorum lingua Celtae, nostra Galli appellantur. Hi omnes lingua, " +
+				"institutis, legibus inter se differunt.",
+			want: "Latin",
+		},
+		{
+			name: "Italian",
+			text: "Nel mezzo del cammin di nostra vita mi ritrovai per una selva oscura, che la diritta " +
+				"via era smarrita. Ahi quanto a dir qual era e cosa dura esta selva selvaggia e aspra e " +
+				"forte che nel pensier rinova la paura!",
+			want: "Italian",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, distance := ClosestLanguage(tt.text)
+			if got != tt.want {
+				t.Errorf("ClosestLanguage = %q (distance %v), want %q", got, distance, tt.want)
+			}
+		})
+	}
+
+	if got, distance := ClosestLanguage("123 ... !?"); got != "" || distance != 0 {
+		t.Errorf("ClosestLanguage without letters = %q, %v, want \"\", 0", got, distance)
+	}
+}
@@ -0,0 +1,783 @@
+package voynich
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxFieldBits bounds the offset and length field widths so that window
+// sizes computed as 1<<bits cannot overflow an int.
+const maxFieldBits = 30
+
+// DecodeOptions controls how DecodeLZ77WithOptions interprets a bitstream.
+// The zero value reproduces the behavior of DecodeLZ77.
+type DecodeOptions struct {
+	// BitOrder selects how literal, offset and length fields are read.
+	BitOrder BitOrder
+
+	// LiteralBits is the width of a literal field. Zero means 8 bits.
+	LiteralBits int
+
+	// Alphabet maps literal codes to symbols: code i decodes to Alphabet[i]
+	// and codes past the end of the alphabet are dropped, so with literals
+	// wider than 8 bits it can map to any Unicode glyph set. When nil,
+	// codes are read as Unicode code points (ASCII for 8-bit literals) and
+	// codes that are not valid code points are dropped. It may not
+	// hold more symbols than a literal can address; a binary-glyph
+	// hypothesis, for example, uses LiteralBits 1 with a two-symbol
+	// Alphabet.
+	Alphabet []rune
+
+	// Accept reports whether a decoded literal symbol is kept; rejected
+	// literals are dropped from the output. When nil, a nil Alphabet keeps
+	// only PrintableASCII and a non-nil Alphabet keeps every symbol. Use
+	// PrintableUnicode to keep code points above 127 without an Alphabet.
+	// Sweeps share one DecodeOptions among concurrent decodes, so a filter
+	// used there must be safe for concurrent use.
+	Accept func(rune) bool
+
+	// WindowSize bounds the sliding window independently of the offset
+	// field, for variants whose dictionary is smaller than the addressable
+	// range. Zero means 1<<offsetBits.
+	WindowSize int
+
+	// OffsetMode selects whether back-reference offsets count back from
+	// the end of the window or forward from its start.
+	OffsetMode OffsetMode
+
+	// MinMatch biases every back-reference length, LZSS style: the copy
+	// length is the stored length field plus MinMatch. This shifts the
+	// representable lengths from 0..2^lengthBits-1 to
+	// MinMatch..MinMatch+2^lengthBits-1, so with MinMatch > 0 a stored 0 is
+	// a valid reference rather than a skipped one.
+	MinMatch int
+
+	// Strict makes an invalid back-reference (an offset outside the
+	// window, or length 0) an error. By default such references are
+	// skipped and counted in DecodeResult.SkippedRefs.
+	Strict bool
+
+	// MaxOutput, when positive, limits the decoded output to that many
+	// symbols. A command that would take the output past the limit is not
+	// applied and the decode stops with an error wrapping ErrOutputLimit,
+	// which guards sweeps against streams whose overlapping references
+	// expand without bound. Zero means no limit.
+	MaxOutput int
+
+	// MaxCorrections, when positive, enables an experimental error-tolerant
+	// mode for noisy transcriptions. On an invalid back-reference the
+	// decoder flips single bits of its offset and length fields, starting
+	// from the last bit read, and applies the first variant that is a valid
+	// reference, counting it in DecodeResult.Corrections. Once the budget
+	// of corrections is spent, invalid references are handled as usual. A
+	// correction is only a guess that makes the stream decodable, not
+	// proof of a transcription error.
+	MaxCorrections int
+
+	// EOSPattern, when not empty, is an end-of-stream marker: a pattern of
+	// '0'/'1' bits that ends the decode where a command would start, so
+	// padding after the marker is ignored. DecodeResult reports where it
+	// was found. A marker overlapping the end of the stream is not a
+	// match, and without a marker the whole stream is decoded.
+	EOSPattern string
+
+	// Trace, when set, is called after each literal and back-reference is
+	// decoded, for inspecting a decode that looks wrong. A nil Trace costs
+	// nothing. Sweeps call it from every worker at once, interleaving the
+	// commands of different combinations, so a collector used there must
+	// be safe for concurrent use; trace a single decode where possible.
+	Trace func(DecodeCommand)
+}
+
+// ErrOutputLimit is returned, wrapped with the failing position, when a
+// decode would exceed DecodeOptions.MaxOutput.
+var ErrOutputLimit = errors.New("output limit exceeded")
+
+// DecodeCommand describes one decoded command for DecodeOptions.Trace.
+type DecodeCommand struct {
+	// Position is the stream position of the command's flag bit and End
+	// the position just after its last field.
+	Position int
+	End      int
+
+	// Reference is true for a back-reference and false for a literal.
+	Reference bool
+
+	// Code is the literal's symbol code; it is 0 for back-references.
+	Code int
+
+	// Offset and Length are the back-reference fields, with MinMatch
+	// already added to Length; they are 0 for literals.
+	Offset int
+	Length int
+
+	// Output holds the symbols the command appended to the output. It is
+	// empty for a filtered literal or a skipped back-reference.
+	Output string
+}
+
+// OffsetMode selects how a back-reference offset locates its source in the
+// sliding window.
+type OffsetMode int
+
+const (
+	// FromEnd treats the offset as a distance back from the current end of
+	// the window, so offset 1 is the most recent symbol. Offset 0 is
+	// invalid.
+	FromEnd OffsetMode = iota
+	// FromStart treats the offset as an absolute index from the start of
+	// the window, so offset 0 is the oldest symbol still in the window.
+	FromStart
+)
+
+// String returns the short name used in reports.
+func (m OffsetMode) String() string {
+	switch m {
+	case FromEnd:
+		return "end"
+	case FromStart:
+		return "start"
+	default:
+		return "unknown"
+	}
+}
+
+// DecodeResult is the output of a decode together with statistics about the
+// commands it contained.
+type DecodeResult struct {
+	// Output is the decoded text.
+	Output string
+
+	// SkippedRefs counts invalid back-references skipped in lenient mode.
+	// Many skipped references suggest the parameters are wrong.
+	SkippedRefs int
+
+	// LiteralCount counts the literal commands read from the stream.
+	LiteralCount int
+
+	// FilteredLiterals counts literals dropped because their code has no
+	// symbol or the symbol was rejected by the filter. A high count is
+	// strong evidence that the parameters are wrong.
+	FilteredLiterals int
+
+	// LiteralBytes counts output symbols produced by literals and
+	// ReferenceBytes those copied by back-references. A stream that is
+	// almost all literals under the given parameters is probably using the
+	// wrong parameterization.
+	LiteralBytes   int
+	ReferenceBytes int
+
+	// ReferenceCount counts the back-references applied to the output.
+	ReferenceCount int
+
+	// Corrections counts the back-references repaired by a bit flip under
+	// DecodeOptions.MaxCorrections.
+	Corrections int
+
+	// StoppedAtEOS reports whether the decode ended at the
+	// DecodeOptions.EOSPattern marker, which starts at stream position
+	// EOSPosition.
+	StoppedAtEOS bool
+	EOSPosition  int
+}
+
+// literalWidth returns the number of bits consumed by a literal.
+func (o DecodeOptions) literalWidth() int {
+	if o.LiteralBits == 0 {
+		return 8
+	}
+	return o.LiteralBits
+}
+
+// literalSymbol maps a literal code to its output symbol. The boolean is
+// false when the code has no symbol, or the symbol is not accepted, and the
+// literal should be dropped.
+func (o DecodeOptions) literalSymbol(code int) (rune, bool) {
+	var symbol rune
+	if o.Alphabet == nil {
+		symbol = rune(code)
+		if !utf8.ValidRune(symbol) {
+			return 0, false
+		}
+	} else {
+		if code >= len(o.Alphabet) {
+			return 0, false
+		}
+		symbol = o.Alphabet[code]
+	}
+
+	switch {
+	case o.Accept != nil:
+		return symbol, o.Accept(symbol)
+	case o.Alphabet == nil:
+		// Default ASCII table: printable characters only
+		return symbol, PrintableASCII(symbol)
+	default:
+		return symbol, true
+	}
+}
+
+// PrintableASCII reports whether r is a printable ASCII character, 32..126.
+// It is the default literal filter of the decoder.
+func PrintableASCII(r rune) bool {
+	return r >= 32 && r <= 126
+}
+
+// PrintableUnicode reports whether r is a printable Unicode character, as
+// defined by unicode.IsPrint. Use it as DecodeOptions.Accept with literals
+// wider than 8 bits to decode code points outside ASCII.
+func PrintableUnicode(r rune) bool {
+	return unicode.IsPrint(r)
+}
+
+// AcceptRunes returns a literal filter for DecodeOptions.Accept that keeps
+// exactly the given symbols.
+func AcceptRunes(symbols []rune) func(rune) bool {
+	allowed := make(map[rune]bool, len(symbols))
+	for _, symbol := range symbols {
+		allowed[symbol] = true
+	}
+	return func(r rune) bool { return allowed[r] }
+}
+
+// DecodeLZ77 attempts to decompress a bitstream using LZ77-like algorithm.
+// On a truncated stream it returns the output decoded so far together with
+// an error describing where the stream ended.
+func DecodeLZ77(bitStream string, offsetBits, lengthBits int) (string, error) {
+	result, err := DecodeLZ77WithOptions(bitStream, offsetBits, lengthBits, DecodeOptions{})
+	return result.Output, err
+}
+
+// DecodeLZ77WithOptions is DecodeLZ77 with additional control over how the
+// bitstream is interpreted. It returns the decoded output with command
+// statistics; on error the result holds everything decoded before it.
+func DecodeLZ77WithOptions(bitStream string, offsetBits, lengthBits int, opts DecodeOptions) (DecodeResult, error) {
+	decoder, err := newLZ77Decoder(offsetBits, lengthBits, opts)
+	if err != nil {
+		return DecodeResult{}, err
+	}
+	return decoder.decode(bitStream)
+}
+
+// decode decodes the whole of bitStream, or up to the end-of-stream marker,
+// and returns the result; on error it holds everything decoded before it.
+func (d *lz77Decoder) decode(bitStream string) (DecodeResult, error) {
+	// The loop condition guarantees at least one bit remains, so the
+	// command flag can always be read; step checks that the literal or
+	// back-reference fields that follow it are complete.
+	position := 0
+	for position < len(bitStream) {
+		if stop, _ := d.endOfStream(bitStream, position, true); stop {
+			break
+		}
+		var err error
+		if position, err = d.step(bitStream, position); err != nil {
+			return d.result(), err
+		}
+	}
+
+	return d.result(), nil
+}
+
+// lz77Decoder holds the state that persists between commands: the field
+// widths, the sliding window and the output decoded so far.
+type lz77Decoder struct {
+	offsetBits  int
+	lengthBits  int
+	literalBits int
+	opts        DecodeOptions
+
+	window ringWindow // Sliding window/dictionary
+	output bytes.Buffer
+	stats  DecodeResult // Command counts; Output is filled by result
+
+	// base is the absolute stream position of index 0 of the bits passed
+	// to step, so that error positions refer to the whole stream.
+	base int
+}
+
+// newLZ77Decoder validates the field widths and returns a decoder with an
+// empty window.
+func newLZ77Decoder(offsetBits, lengthBits int, opts DecodeOptions) (*lz77Decoder, error) {
+	if offsetBits < 1 || offsetBits > maxFieldBits {
+		return nil, fmt.Errorf("offsetBits must be between 1 and %d, got %d", maxFieldBits, offsetBits)
+	}
+	if lengthBits < 1 || lengthBits > maxFieldBits {
+		return nil, fmt.Errorf("lengthBits must be between 1 and %d, got %d", maxFieldBits, lengthBits)
+	}
+	if opts.LiteralBits < 0 || opts.LiteralBits > maxFieldBits {
+		return nil, fmt.Errorf("LiteralBits must be between 0 and %d, got %d", maxFieldBits, opts.LiteralBits)
+	}
+	if len(opts.Alphabet) > 1<<opts.literalWidth() {
+		return nil, fmt.Errorf("Alphabet of %d symbols does not fit in %d literal bits",
+			len(opts.Alphabet), opts.literalWidth())
+	}
+	if opts.WindowSize < 0 {
+		return nil, fmt.Errorf("WindowSize must not be negative, got %d", opts.WindowSize)
+	}
+	if opts.MinMatch < 0 {
+		return nil, fmt.Errorf("MinMatch must not be negative, got %d", opts.MinMatch)
+	}
+	if opts.MaxOutput < 0 {
+		return nil, fmt.Errorf("MaxOutput must not be negative, got %d", opts.MaxOutput)
+	}
+	if opts.MaxCorrections < 0 {
+		return nil, fmt.Errorf("MaxCorrections must not be negative, got %d", opts.MaxCorrections)
+	}
+	if strings.Trim(opts.EOSPattern, "01") != "" {
+		return nil, fmt.Errorf("EOSPattern must contain only '0' and '1', got %q", opts.EOSPattern)
+	}
+
+	windowSize := opts.WindowSize
+	if windowSize == 0 {
+		windowSize = 1 << offsetBits
+	}
+
+	return &lz77Decoder{
+		offsetBits:  offsetBits,
+		lengthBits:  lengthBits,
+		literalBits: opts.literalWidth(),
+		opts:        opts,
+		window:      ringWindow{size: windowSize},
+	}, nil
+}
+
+// ringWindow is the decoder's sliding window: the last size symbols
+// written, held in a ring buffer so that appending never shifts or
+// reallocates the window once it is full. The buffer grows on demand up to
+// size, so a wide offset field does not allocate its whole window upfront.
+type ringWindow struct {
+	buf   []rune
+	size  int
+	total int // Symbols written so far; the window holds the last len() of them
+}
+
+// len returns the number of symbols currently in the window.
+func (w *ringWindow) len() int {
+	if w.total < w.size {
+		return w.total
+	}
+	return w.size
+}
+
+// first returns the absolute position of the oldest symbol in the window.
+func (w *ringWindow) first() int {
+	return w.total - w.len()
+}
+
+// at returns the symbol at absolute position pos, which must lie in
+// [first(), total).
+func (w *ringWindow) at(pos int) rune {
+	return w.buf[pos%w.size]
+}
+
+// push appends a symbol, dropping the oldest one when the window is full.
+func (w *ringWindow) push(r rune) {
+	if len(w.buf) < w.size {
+		w.buf = append(w.buf, r)
+	} else {
+		w.buf[w.total%w.size] = r
+	}
+	w.total++
+}
+
+// result returns the output decoded so far with the command statistics.
+func (d *lz77Decoder) result() DecodeResult {
+	result := d.stats
+	result.Output = d.output.String()
+	return result
+}
+
+// endOfStream checks for the EOSPattern marker at position in the current
+// bits and records it in the statistics when it is found. When final is
+// false more bits may follow, so a remainder that is a proper prefix of the
+// marker cannot be decided yet and wait is true.
+func (d *lz77Decoder) endOfStream(bitStream string, position int, final bool) (stop, wait bool) {
+	pattern := d.opts.EOSPattern
+	if pattern == "" {
+		return false, false
+	}
+	rest := bitStream[position:]
+	if strings.HasPrefix(rest, pattern) {
+		d.stats.StoppedAtEOS = true
+		d.stats.EOSPosition = d.base + position
+		return true, false
+	}
+	return false, !final && strings.HasPrefix(pattern, rest)
+}
+
+// incompleteError reports a command cut off by the end of the available bits.
+type incompleteError struct {
+	command  string
+	position int
+}
+
+func (e *incompleteError) Error() string {
+	return fmt.Sprintf("incomplete %s at position %d", e.command, e.position)
+}
+
+// referenceStart returns the window index a back-reference with the given
+// offset copies from, and false if the offset points outside the window.
+// Until the window is full it holds the whole output so far, so early
+// references resolve against that partial window and may reach back to the
+// first decoded symbol; only offsets before the start of the output are
+// rejected.
+func (d *lz77Decoder) referenceStart(offset int) (int, bool) {
+	if d.opts.OffsetMode == FromStart {
+		return offset, offset < d.window.len()
+	}
+	return d.window.len() - offset, offset > 0 && offset <= d.window.len()
+}
+
+// checkOutputLimit returns an error if appending n symbols would take the
+// output past MaxOutput.
+func (d *lz77Decoder) checkOutputLimit(n, commandStart int) error {
+	if d.opts.MaxOutput > 0 && d.window.total+n > d.opts.MaxOutput {
+		return fmt.Errorf("%w: %d symbols would exceed %d at position %d",
+			ErrOutputLimit, d.window.total+n, d.opts.MaxOutput, d.base+commandStart)
+	}
+	return nil
+}
+
+// invalidCharacter reports a non-bit character at position in the current
+// bits.
+func (d *lz77Decoder) invalidCharacter(char byte, position int) error {
+	return fmt.Errorf("invalid bitstream character %q at position %d", char, d.base+position)
+}
+
+// invalidBit returns the index of the first character of field that is not
+// '0' or '1', or -1 if there is none.
+func invalidBit(field string) int {
+	for i := 0; i < len(field); i++ {
+		if field[i] != '0' && field[i] != '1' {
+			return i
+		}
+	}
+	return -1
+}
+
+// step decodes the command starting at bitStream[position], which must be
+// in range, and returns the position of the next command. If the command
+// runs past the end of bitStream it returns an *incompleteError, and if it
+// contains a character other than '0' or '1' or would exceed MaxOutput it
+// returns an error; in all cases the decoder state is left unchanged.
+func (d *lz77Decoder) step(bitStream string, position int) (int, error) {
+	commandStart := position
+
+	// Read command flag (1 bit)
+	flag := bitStream[position]
+	position++
+	if flag != '0' && flag != '1' {
+		return commandStart, d.invalidCharacter(flag, commandStart)
+	}
+
+	if flag == '0' {
+		// Literal character: read next literalBits bits as a symbol code.
+		// The field occupies [position, position+literalBits).
+		if position+d.literalBits > len(bitStream) {
+			return commandStart, &incompleteError{"literal", d.base + position}
+		}
+		if i := invalidBit(bitStream[position : position+d.literalBits]); i >= 0 {
+			return commandStart, d.invalidCharacter(bitStream[position+i], position+i)
+		}
+
+		// Convert binary to symbol code
+		charCode := readBits(bitStream[position:position+d.literalBits], d.opts.BitOrder)
+		character, ok := d.opts.literalSymbol(charCode)
+		if ok {
+			if err := d.checkOutputLimit(1, commandStart); err != nil {
+				return commandStart, err
+			}
+		}
+		position += d.literalBits
+		d.stats.LiteralCount++
+
+		// Add characters the alphabet defines only
+		outputStart := d.output.Len()
+		if ok {
+			d.output.WriteRune(character)
+			d.window.push(character)
+			d.stats.LiteralBytes++
+		} else {
+			d.stats.FilteredLiterals++
+		}
+		if d.opts.Trace != nil {
+			d.opts.Trace(DecodeCommand{
+				Position: d.base + commandStart,
+				End:      d.base + position,
+				Code:     charCode,
+				Output:   string(d.output.Bytes()[outputStart:]),
+			})
+		}
+
+	} else {
+		// Back-reference: read (offsetBits + lengthBits) for (distance, length) tuple.
+		// The fields occupy [position, position+offsetBits+lengthBits).
+		if position+d.offsetBits+d.lengthBits > len(bitStream) {
+			return commandStart, &incompleteError{"back-reference", d.base + position}
+		}
+		if i := invalidBit(bitStream[position : position+d.offsetBits+d.lengthBits]); i >= 0 {
+			return commandStart, d.invalidCharacter(bitStream[position+i], position+i)
+		}
+
+		fields := bitStream[position : position+d.offsetBits+d.lengthBits]
+		position += d.offsetBits + d.lengthBits
+		offset, length := d.readReference(fields)
+
+		// Validate and apply back-reference
+		startPos, ok := d.referenceStart(offset)
+		corrected := false
+		if (!ok || length == 0) && d.stats.Corrections < d.opts.MaxCorrections {
+			if fixedOffset, fixedLength, fixedStart, found := d.correctReference(fields); found {
+				offset, length, startPos, ok, corrected = fixedOffset, fixedLength, fixedStart, true, true
+			}
+		}
+		if !ok || length == 0 {
+			if d.opts.Strict {
+				return commandStart, fmt.Errorf("invalid back-reference (offset %d, length %d, window %d) at position %d",
+					offset, length, d.window.len(), d.base+commandStart)
+			}
+			d.stats.SkippedRefs++
+			d.traceReference(commandStart, offset, length, d.output.Len())
+			return position, nil // Invalid reference, skip
+		}
+		if err := d.checkOutputLimit(length, commandStart); err != nil {
+			return commandStart, err
+		}
+
+		// Copy one character at a time, appending as we go, so that a
+		// reference that runs past the current end (an overlapping copy)
+		// repeats the referenced window like run-length coding. The source
+		// is tracked by absolute position; it always trails the write
+		// position by at most the window size, so it is never overwritten
+		// before it is read.
+		source := d.window.first() + startPos
+		outputStart := d.output.Len()
+		for i := 0; i < length; i++ {
+			character := d.window.at(source + i)
+			d.output.WriteRune(character)
+			d.window.push(character)
+		}
+		d.stats.ReferenceCount++
+		d.stats.ReferenceBytes += length
+		if corrected {
+			d.stats.Corrections++ // Counted only once the repaired copy is applied
+		}
+		d.traceReference(commandStart, offset, length, outputStart)
+	}
+
+	return position, nil
+}
+
+// readReference interprets the offset and length fields of a
+// back-reference; the stored length is biased by MinMatch.
+func (d *lz77Decoder) readReference(fields string) (offset, length int) {
+	offset = readBits(fields[:d.offsetBits], d.opts.BitOrder)
+	length = readBits(fields[d.offsetBits:], d.opts.BitOrder) + d.opts.MinMatch
+	return offset, length
+}
+
+// correctReference looks for a single bit flip that turns the fields of an
+// invalid back-reference into a valid one, trying the last bit first, and
+// returns the corrected reference with its window index.
+func (d *lz77Decoder) correctReference(fields string) (offset, length, startPos int, ok bool) {
+	flipped := []byte(fields)
+	for i := len(flipped) - 1; i >= 0; i-- {
+		flipped[i] ^= '0' ^ '1' // Toggles between '0' and '1'
+		offset, length = d.readReference(string(flipped))
+		if startPos, ok = d.referenceStart(offset); ok && length > 0 {
+			return offset, length, startPos, true
+		}
+		flipped[i] ^= '0' ^ '1'
+	}
+	return 0, 0, 0, false
+}
+
+// traceReference reports a back-reference starting at commandStart to the
+// Trace callback, if any; its output is everything written from
+// outputStart on.
+func (d *lz77Decoder) traceReference(commandStart, offset, length, outputStart int) {
+	if d.opts.Trace == nil {
+		return
+	}
+	d.opts.Trace(DecodeCommand{
+		Position:  d.base + commandStart,
+		End:       d.base + commandStart + 1 + d.offsetBits + d.lengthBits,
+		Reference: true,
+		Offset:    offset,
+		Length:    length,
+		Output:    string(d.output.Bytes()[outputStart:]),
+	})
+}
+
+// DecodedRune is one decoded symbol together with the span of the stream
+// that produced it.
+type DecodedRune struct {
+	Rune rune
+
+	// BitStart and BitEnd delimit the command that emitted the rune,
+	// [BitStart, BitEnd) in stream positions. All runes copied by one
+	// back-reference share its span.
+	BitStart int
+	BitEnd   int
+
+	// FromReference is true for runes copied by a back-reference and false
+	// for literals.
+	FromReference bool
+}
+
+// DecodeLZ77Runes is DecodeLZ77WithOptions returning each decoded rune with
+// the bit span of the command that produced it, for mapping output back to
+// the stream. Consecutive commands have adjacent spans, so the spans tile
+// the decoded stream except where a filtered literal or skipped reference
+// produced no rune. A Trace in opts is still called. On error the runes
+// decoded so far are returned.
+func DecodeLZ77Runes(bitStream string, offsetBits, lengthBits int, opts DecodeOptions) ([]DecodedRune, error) {
+	var runes []DecodedRune
+	trace := opts.Trace
+	opts.Trace = func(cmd DecodeCommand) {
+		for _, char := range cmd.Output {
+			runes = append(runes, DecodedRune{
+				Rune:          char,
+				BitStart:      cmd.Position,
+				BitEnd:        cmd.End,
+				FromReference: cmd.Reference,
+			})
+		}
+		if trace != nil {
+			trace(cmd)
+		}
+	}
+
+	_, err := DecodeLZ77WithOptions(bitStream, offsetBits, lengthBits, opts)
+	return runes, err
+}
+
+// ValidateLZ77 checks whether a bitstream parses as LZ77 under the given
+// field widths, as DecodeLZ77 reads it, without building the output: every
+// command must be complete, made of '0' and '1' characters only, and every
+// back-reference must have a non-zero length and an offset inside the
+// window. It only tracks how many symbols the window holds, so it is much
+// cheaper than a decode for pruning a large sweep. The string explains the
+// first problem found and is empty for a valid stream.
+func ValidateLZ77(bitStream string, offsetBits, lengthBits int) (bool, string) {
+	decoder, err := newLZ77Decoder(offsetBits, lengthBits, DecodeOptions{})
+	if err != nil {
+		return false, err.Error()
+	}
+
+	windowLen := 0 // Symbols in the window; the output itself is not kept
+	position := 0
+	for position < len(bitStream) {
+		commandStart := position
+		flag := bitStream[position]
+		position++
+
+		var width int
+		var command string
+		switch flag {
+		case '0':
+			width, command = decoder.literalBits, "literal"
+		case '1':
+			width, command = offsetBits+lengthBits, "back-reference"
+		default:
+			return false, fmt.Sprintf("invalid bitstream character %q at position %d", flag, commandStart)
+		}
+		if position+width > len(bitStream) {
+			return false, (&incompleteError{command, position}).Error()
+		}
+		if i := invalidBit(bitStream[position : position+width]); i >= 0 {
+			return false, fmt.Sprintf("invalid bitstream character %q at position %d", bitStream[position+i], position+i)
+		}
+
+		if flag == '0' {
+			code := readBits(bitStream[position:position+width], MSBFirst)
+			if _, ok := decoder.opts.literalSymbol(code); ok {
+				windowLen++
+			}
+		} else {
+			offset := readBits(bitStream[position:position+offsetBits], MSBFirst)
+			length := readBits(bitStream[position+offsetBits:position+width], MSBFirst)
+			if offset == 0 || offset > windowLen || length == 0 {
+				return false, fmt.Sprintf("invalid back-reference (offset %d, length %d, window %d) at position %d",
+					offset, length, windowLen, commandStart)
+			}
+			windowLen += length
+		}
+		position += width
+
+		if windowLen > decoder.window.size {
+			windowLen = decoder.window.size
+		}
+	}
+
+	return true, ""
+}
+
+// EncodeLZ77 compresses printable ASCII text into the bitstream format read by
+// DecodeLZ77. Each command is a 1-bit flag followed by either an 8-bit literal
+// (flag 0) or an (offset, length) back-reference (flag 1), all written
+// MSB-first. Matches are found by greedy longest-match search within the
+// sliding window of 1<<offsetBits characters, and a back-reference is only
+// emitted when it is shorter than spelling the match out as literals.
+func EncodeLZ77(text string, offsetBits, lengthBits int) (string, error) {
+	if offsetBits < 1 || offsetBits > maxFieldBits {
+		return "", fmt.Errorf("offsetBits must be between 1 and %d, got %d", maxFieldBits, offsetBits)
+	}
+	if lengthBits < 1 || lengthBits > maxFieldBits {
+		return "", fmt.Errorf("lengthBits must be between 1 and %d, got %d", maxFieldBits, lengthBits)
+	}
+
+	// The decoder drops non-printable literals, so they cannot round-trip
+	for i := 0; i < len(text); i++ {
+		if text[i] < 32 || text[i] > 126 {
+			return "", fmt.Errorf("character %q at index %d is not printable ASCII", text[i], i)
+		}
+	}
+
+	windowSize := 1 << offsetBits
+	maxOffset := windowSize - 1
+	maxLength := 1<<lengthBits - 1
+	referenceCost := 1 + offsetBits + lengthBits
+	literalCost := 1 + 8
+
+	var bitStream strings.Builder
+	position := 0
+
+	for position < len(text) {
+		// Search the window for the longest match starting at position.
+		// Matches may run past position (overlapping copy), which the
+		// decoder expands one character at a time.
+		bestLength, bestOffset := 0, 0
+		for offset := 1; offset <= maxOffset && offset <= position; offset++ {
+			start := position - offset
+			length := 0
+			for length < maxLength && position+length < len(text) &&
+				text[start+length] == text[position+length] {
+				length++
+			}
+			if length > bestLength {
+				bestLength, bestOffset = length, offset
+			}
+		}
+
+		if bestLength*literalCost > referenceCost {
+			// Back-reference: flag 1, then offset and length fields
+			bitStream.WriteByte('1')
+			writeBits(&bitStream, bestOffset, offsetBits)
+			writeBits(&bitStream, bestLength, lengthBits)
+			position += bestLength
+		} else {
+			// Literal: flag 0, then the 8-bit character code
+			bitStream.WriteByte('0')
+			writeBits(&bitStream, int(text[position]), 8)
+			position++
+		}
+	}
+
+	return bitStream.String(), nil
+}
@@ -0,0 +1,641 @@
+package voynich
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestDecodeLZ77MalformedInput checks that truncated and garbage
+// bitstreams return an error or partial output instead of panicking. All
+// cases use 10-bit offsets and 4-bit lengths.
+func TestDecodeLZ77MalformedInput(t *testing.T) {
+	const literalA = "001100001" // Literal 'a'
+	tests := []struct {
+		name      string
+		bitStream string
+		strict    bool
+		want      string
+		wantErr   string // Substring of the error; empty for none
+		skipped   int
+	}{
+		{name: "empty", bitStream: "", want: ""},
+		{name: "lone flag", bitStream: "1", wantErr: "incomplete back-reference at position 1"},
+		{name: "lone literal flag", bitStream: "0", wantErr: "incomplete literal at position 1"},
+		{name: "truncated literal", bitStream: literalA + "0011", want: "a", wantErr: "incomplete literal at position 10"},
+		{
+			name:      "offset beyond window",
+			bitStream: literalA + "1" + "0000000101" + "0010",
+			want:      "a",
+			skipped:   1,
+		},
+		{
+			name:      "offset beyond window strict",
+			bitStream: literalA + "1" + "0000000101" + "0010",
+			strict:    true,
+			want:      "a",
+			wantErr:   "invalid back-reference (offset 5, length 2, window 1) at position 9",
+		},
+		{
+			name:      "all-ones length at end",
+			bitStream: literalA + "1" + "0000000001" + "1111",
+			want:      strings.Repeat("a", 16),
+		},
+		{
+			name:      "all-ones length cut off",
+			bitStream: literalA + "1" + "0000000001" + "111",
+			want:      "a",
+			wantErr:   "incomplete back-reference at position 10",
+		},
+		{name: "all ones", bitStream: strings.Repeat("1", 15), skipped: 1},
+		{name: "garbage character", bitStream: "0011x0001", wantErr: `invalid bitstream character 'x' at position 4`},
+		{name: "garbage flag", bitStream: literalA + "2", want: "a", wantErr: `invalid bitstream character '2' at position 9`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := DecodeLZ77WithOptions(tt.bitStream, 10, 4, DecodeOptions{Strict: tt.strict})
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if result.Output != tt.want {
+				t.Errorf("output = %q, want %q", result.Output, tt.want)
+			}
+			if result.SkippedRefs != tt.skipped {
+				t.Errorf("SkippedRefs = %d, want %d", result.SkippedRefs, tt.skipped)
+			}
+		})
+	}
+}
+
+// literalBits returns the 9-bit literal command for an ASCII character.
+func literalBits(c byte) string {
+	var b strings.Builder
+	b.WriteByte('0')
+	writeBits(&b, int(c), 8)
+	return b.String()
+}
+
+// referenceBits returns the back-reference command for the given offset
+// and length with 10-bit offsets and 4-bit lengths.
+func referenceBits(offset, length int) string {
+	var b strings.Builder
+	b.WriteByte('1')
+	writeBits(&b, offset, 10)
+	writeBits(&b, length, 4)
+	return b.String()
+}
+
+func TestMaxCorrections(t *testing.T) {
+	// Offset 3 with its high bit flipped by noise reads as 515
+	corrupted := literalBits('a') + literalBits('b') + literalBits('c') + referenceBits(515, 6)
+
+	result, err := DecodeLZ77WithOptions(corrupted, 10, 4, DecodeOptions{Strict: true, MaxCorrections: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "abcabcabc" || result.Corrections != 1 {
+		t.Errorf("got %q with %d corrections, want %q with 1", result.Output, result.Corrections, "abcabcabc")
+	}
+
+	// Without corrections the strict decode reports the original fields
+	_, err = DecodeLZ77WithOptions(corrupted, 10, 4, DecodeOptions{Strict: true})
+	if err == nil || !strings.Contains(err.Error(), "offset 515, length 6") {
+		t.Errorf("error = %v, want the invalid offset 515 reported", err)
+	}
+
+	// The budget is spent after one correction
+	twice := corrupted + referenceBits(515, 6)
+	result, _ = DecodeLZ77WithOptions(twice, 10, 4, DecodeOptions{MaxCorrections: 1})
+	if result.Corrections != 1 || result.SkippedRefs != 1 {
+		t.Errorf("Corrections = %d, SkippedRefs = %d, want 1 and 1", result.Corrections, result.SkippedRefs)
+	}
+}
+
+func TestMaxCorrectionsNotCountedPastOutputLimit(t *testing.T) {
+	corrupted := literalBits('a') + literalBits('b') + literalBits('c') + referenceBits(515, 6)
+	result, err := DecodeLZ77WithOptions(corrupted, 10, 4, DecodeOptions{MaxCorrections: 1, MaxOutput: 5})
+	if !errors.Is(err, ErrOutputLimit) {
+		t.Fatalf("error = %v, want ErrOutputLimit", err)
+	}
+	if result.Corrections != 0 || result.Output != "abc" {
+		t.Errorf("got %q with %d corrections, want %q with 0", result.Output, result.Corrections, "abc")
+	}
+}
+
+func TestTraceCalledOncePerCommand(t *testing.T) {
+	// Two literals, an applied reference, a skipped reference and a
+	// filtered literal
+	bitStream := literalBits('a') + literalBits('b') + referenceBits(2, 4) + referenceBits(0, 3) + literalBits(7)
+
+	var commands []DecodeCommand
+	result, err := DecodeLZ77WithOptions(bitStream, 10, 4, DecodeOptions{
+		Trace: func(cmd DecodeCommand) { commands = append(commands, cmd) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commands) != 5 {
+		t.Fatalf("Trace called %d times, want 5", len(commands))
+	}
+	if want := result.LiteralCount + result.ReferenceCount + result.SkippedRefs; len(commands) != want {
+		t.Errorf("Trace called %d times, want one per command (%d)", len(commands), want)
+	}
+
+	var output strings.Builder
+	for i, cmd := range commands {
+		output.WriteString(cmd.Output)
+		if i > 0 && cmd.Position != commands[i-1].End {
+			t.Errorf("command %d starts at %d, want %d", i, cmd.Position, commands[i-1].End)
+		}
+	}
+	if output.String() != result.Output {
+		t.Errorf("traced output %q, want %q", output.String(), result.Output)
+	}
+	if !commands[2].Reference || commands[2].Offset != 2 || commands[2].Length != 4 || commands[3].Output != "" {
+		t.Errorf("unexpected reference commands %+v, %+v", commands[2], commands[3])
+	}
+}
+
+func TestTraceConcurrentSweep(t *testing.T) {
+	bitStream, err := EncodeLZ77(phaseText, 10, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	calls := 0
+	opts := DecodeOptions{Trace: func(DecodeCommand) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+	}}
+	results := SweepLZ77WithOptions(bitStream, []int{9, 10, 11}, []int{3, 4, 5}, opts)
+
+	want := 0
+	for _, result := range results {
+		want += result.LiteralCount + result.ReferenceCount + result.SkippedRefs
+	}
+	if calls != want {
+		t.Errorf("Trace called %d times across the sweep, want %d", calls, want)
+	}
+}
+
+func TestReferenceWhileWindowFills(t *testing.T) {
+	// With a 1024-symbol window every reference below lands in the fill
+	// phase and resolves against the output so far, back to its first
+	// symbol
+	bitStream := literalBits('a') + literalBits('b') + literalBits('c') +
+		referenceBits(3, 3) + referenceBits(6, 1)
+	result, err := DecodeLZ77WithOptions(bitStream, 10, 4, DecodeOptions{Strict: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "abcabca" || result.SkippedRefs != 0 {
+		t.Errorf("got %q with %d skipped references, want %q with none", result.Output, result.SkippedRefs, "abcabca")
+	}
+
+	// One step past the first symbol is outside the partial window
+	_, err = DecodeLZ77WithOptions(literalBits('a')+referenceBits(2, 1), 10, 4, DecodeOptions{Strict: true})
+	if err == nil || !strings.Contains(err.Error(), "offset 2, length 1, window 1") {
+		t.Errorf("error = %v, want an invalid reference for offset 2 in a 1-symbol window", err)
+	}
+}
+
+func TestRingWindowWraps(t *testing.T) {
+	// A 4-symbol window over a much longer output: every reference uses
+	// offset 4, the oldest symbol in the window, after the ring has wrapped
+	const windowSize = 4
+	bitStream := literalBits('a') + literalBits('b') + literalBits('c') + literalBits('d')
+	want := []byte("abcd")
+	for i := 0; i < 20; i++ {
+		bitStream += literalBits('e'+byte(i%3)) + referenceBits(windowSize, 3)
+		want = append(want, 'e'+byte(i%3))
+		for j := 0; j < 3; j++ {
+			want = append(want, want[len(want)-windowSize])
+		}
+	}
+
+	result, err := DecodeLZ77WithOptions(bitStream, 10, 4, DecodeOptions{Strict: true, WindowSize: windowSize})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != string(want) {
+		t.Errorf("output = %q, want %q", result.Output, want)
+	}
+
+	// One past the window size is out of range even with 10-bit offsets
+	_, err = DecodeLZ77WithOptions(bitStream+referenceBits(windowSize+1, 1), 10, 4, DecodeOptions{Strict: true, WindowSize: windowSize})
+	if err == nil || !strings.Contains(err.Error(), "offset 5, length 1, window 4") {
+		t.Errorf("error = %v, want offset 5 rejected for a 4-symbol window", err)
+	}
+}
+
+func TestEncodeLZ77RoundTrip(t *testing.T) {
+	tests := []struct {
+		name                   string
+		text                   string
+		offsetBits, lengthBits int
+	}{
+		{name: "empty", text: "", offsetBits: 10, lengthBits: 4},
+		{name: "no repeats", text: "abcdefghij", offsetBits: 10, lengthBits: 4},
+		{name: "run longer than max length", text: strings.Repeat("a", 200), offsetBits: 10, lengthBits: 4},
+		{name: "overlapping repeat", text: "xy" + strings.Repeat("abc", 50), offsetBits: 10, lengthBits: 4},
+		{name: "repeat beyond window", text: strings.Repeat("the quick brown fox ", 30), offsetBits: 4, lengthBits: 3},
+		{name: "english", text: benchText(8 << 10), offsetBits: 12, lengthBits: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bitStream, err := EncodeLZ77(tt.text, tt.offsetBits, tt.lengthBits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := DecodeLZ77WithOptions(bitStream, tt.offsetBits, tt.lengthBits, DecodeOptions{Strict: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Output != tt.text {
+				t.Errorf("round trip gave %q, want %q", result.Output, tt.text)
+			}
+		})
+	}
+
+	// Long repeats are coded as references, well under 9 bits a character
+	text := strings.Repeat("voynich ", 100)
+	bitStream, _ := EncodeLZ77(text, 10, 4)
+	if len(bitStream) >= len(text)*9/4 {
+		t.Errorf("encoded %d characters in %d bits, want references to compress them", len(text), len(bitStream))
+	}
+}
+
+func TestEncodeLZ77RejectsNonPrintable(t *testing.T) {
+	if _, err := EncodeLZ77("ab\ncd", 10, 4); err == nil || !strings.Contains(err.Error(), "at index 2") {
+		t.Errorf("error = %v, want the newline at index 2 rejected", err)
+	}
+	if _, err := EncodeLZ77("abc", 0, 4); err == nil {
+		t.Error("offsetBits 0 accepted")
+	}
+}
+
+func TestStrictAndLenient(t *testing.T) {
+	tests := []struct {
+		name    string
+		invalid string
+		wantErr string
+	}{
+		{name: "offset 0", invalid: referenceBits(0, 2), wantErr: "invalid back-reference (offset 0, length 2, window 2) at position 18"},
+		{name: "offset past window", invalid: referenceBits(3, 2), wantErr: "invalid back-reference (offset 3, length 2, window 2) at position 18"},
+		{name: "length 0", invalid: referenceBits(1, 0), wantErr: "invalid back-reference (offset 1, length 0, window 2) at position 18"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bitStream := literalBits('a') + literalBits('b') + tt.invalid + literalBits('c')
+
+			// Lenient mode skips the reference and decodes the rest
+			result, err := DecodeLZ77WithOptions(bitStream, 10, 4, DecodeOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Output != "abc" || result.SkippedRefs != 1 {
+				t.Errorf("lenient: got %q with %d skipped, want %q with 1", result.Output, result.SkippedRefs, "abc")
+			}
+
+			// Strict mode stops there with the output so far
+			result, err = DecodeLZ77WithOptions(bitStream, 10, 4, DecodeOptions{Strict: true})
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("strict: error = %v, want %q", err, tt.wantErr)
+			}
+			if result.Output != "ab" || result.SkippedRefs != 0 {
+				t.Errorf("strict: got %q with %d skipped, want %q with 0", result.Output, result.SkippedRefs, "ab")
+			}
+		})
+	}
+}
+
+func TestDecodeStatistics(t *testing.T) {
+	// Three literals, one filtered, and two applied references copying
+	// 4 and 2 symbols, plus one skipped reference
+	bitStream := literalBits('a') + literalBits('b') + literalBits(7) +
+		referenceBits(2, 4) + referenceBits(0, 3) + referenceBits(1, 2) + literalBits('c')
+	result, err := DecodeLZ77WithOptions(bitStream, 10, 4, DecodeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DecodeResult{
+		Output:           "abababbbc",
+		SkippedRefs:      1,
+		LiteralCount:     4,
+		FilteredLiterals: 1,
+		LiteralBytes:     3,
+		ReferenceBytes:   6,
+		ReferenceCount:   2,
+	}
+	if result != want {
+		t.Errorf("got %+v, want %+v", result, want)
+	}
+	if result.LiteralBytes+result.ReferenceBytes != len(result.Output) {
+		t.Errorf("LiteralBytes + ReferenceBytes = %d, want the output length %d",
+			result.LiteralBytes+result.ReferenceBytes, len(result.Output))
+	}
+}
+
+func TestWindowSize(t *testing.T) {
+	// Offset 3 is in range for the 1024-symbol window of 10-bit offsets
+	// but not for a 2-symbol window
+	bitStream := literalBits('a') + literalBits('b') + literalBits('c') + referenceBits(3, 3) + referenceBits(2, 2)
+
+	result, err := DecodeLZ77WithOptions(bitStream, 10, 4, DecodeOptions{})
+	if err != nil || result.Output != "abcabcbc" {
+		t.Errorf("default window: got %q, %v, want %q", result.Output, err, "abcabcbc")
+	}
+	result, err = DecodeLZ77WithOptions(bitStream, 10, 4, DecodeOptions{WindowSize: 2})
+	if err != nil || result.Output != "abcbc" || result.SkippedRefs != 1 {
+		t.Errorf("2-symbol window: got %q with %d skipped, %v, want %q with 1", result.Output, result.SkippedRefs, err, "abcbc")
+	}
+
+	if _, err := DecodeLZ77WithOptions(bitStream, 10, 4, DecodeOptions{WindowSize: -1}); err == nil {
+		t.Error("negative WindowSize accepted")
+	}
+}
+
+func TestMinMatch(t *testing.T) {
+	// A stored length of 0 is invalid without a bias and copies 3 symbols
+	// with MinMatch 3; a stored 1 copies 1 or 4 symbols
+	bitStream := literalBits('a') + literalBits('b') + referenceBits(2, 0) + referenceBits(1, 1)
+
+	result, err := DecodeLZ77WithOptions(bitStream, 10, 4, DecodeOptions{})
+	if err != nil || result.Output != "abb" || result.SkippedRefs != 1 {
+		t.Errorf("MinMatch 0: got %q with %d skipped, %v, want %q with 1", result.Output, result.SkippedRefs, err, "abb")
+	}
+	result, err = DecodeLZ77WithOptions(bitStream, 10, 4, DecodeOptions{MinMatch: 3})
+	if err != nil || result.Output != "ababaaaaa" || result.SkippedRefs != 0 {
+		t.Errorf("MinMatch 3: got %q with %d skipped, %v, want %q with none", result.Output, result.SkippedRefs, err, "ababaaaaa")
+	}
+
+	if _, err := DecodeLZ77WithOptions(bitStream, 10, 4, DecodeOptions{MinMatch: -1}); err == nil {
+		t.Error("negative MinMatch accepted")
+	}
+}
+
+func TestAcceptFilter(t *testing.T) {
+	var bitStream string
+	for _, c := range []byte("abxaby") {
+		bitStream += literalBits(c)
+	}
+
+	all, err := DecodeLZ77WithOptions(bitStream, 10, 4, DecodeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	glyphs, err := DecodeLZ77WithOptions(bitStream, 10, 4, DecodeOptions{Accept: AcceptRunes([]rune("ab"))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all.Output != "abxaby" || glyphs.Output != "abab" {
+		t.Errorf("got %q and %q, want %q and %q", all.Output, glyphs.Output, "abxaby", "abab")
+	}
+	if ShannonEntropy(glyphs.Output) != 1 || ShannonEntropy(all.Output) <= 1 {
+		t.Errorf("entropies %v and %v, want the filter to lower it to 1", ShannonEntropy(all.Output), ShannonEntropy(glyphs.Output))
+	}
+	// Rejected literals are counted, not silently dropped
+	if glyphs.FilteredLiterals != 2 || glyphs.LiteralCount != 6 {
+		t.Errorf("FilteredLiterals = %d of %d, want 2 of 6", glyphs.FilteredLiterals, glyphs.LiteralCount)
+	}
+
+	// PrintableUnicode keeps code points above ASCII that the default drops
+	wide := "0" + "00000000" + "11101001" // 16-bit literal U+00E9
+	decoded, _ := DecodeLZ77WithOptions(wide, 10, 4, DecodeOptions{LiteralBits: 16})
+	printable, _ := DecodeLZ77WithOptions(wide, 10, 4, DecodeOptions{LiteralBits: 16, Accept: PrintableUnicode})
+	if decoded.Output != "" || printable.Output != "é" {
+		t.Errorf("got %q and %q, want %q and %q", decoded.Output, printable.Output, "", "é")
+	}
+}
+
+func TestFilteredLiterals(t *testing.T) {
+	// Control characters, DEL and codes above 127 fall outside the default
+	// printable range
+	var bitStream string
+	for _, c := range []byte{'h', 0, 'i', 7, 127, 200, 255, '!'} {
+		bitStream += literalBits(c)
+	}
+	result, err := DecodeLZ77WithOptions(bitStream, 10, 4, DecodeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "hi!" || result.FilteredLiterals != 5 || result.LiteralCount != 8 {
+		t.Errorf("got %q with %d of %d literals filtered, want %q with 5 of 8",
+			result.Output, result.FilteredLiterals, result.LiteralCount, "hi!")
+	}
+	if result.LiteralBytes != 3 {
+		t.Errorf("LiteralBytes = %d, want 3", result.LiteralBytes)
+	}
+
+	// A code past the end of an Alphabet is filtered too
+	result, _ = DecodeLZ77WithOptions("0"+"00000001"+"0"+"00000010", 10, 4, DecodeOptions{Alphabet: []rune("xy")})
+	if result.Output != "y" || result.FilteredLiterals != 1 {
+		t.Errorf("got %q with %d filtered, want %q with 1", result.Output, result.FilteredLiterals, "y")
+	}
+}
+
+func TestOffsetMode(t *testing.T) {
+	bitStream := literalBits('a') + literalBits('b') + literalBits('c') + referenceBits(1, 2) + referenceBits(0, 1)
+
+	// From the end, offset 1 is the last symbol and offset 0 is invalid;
+	// from the start, offset 1 is the second symbol and offset 0 the first
+	fromEnd, err := DecodeLZ77WithOptions(bitStream, 10, 4, DecodeOptions{OffsetMode: FromEnd})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromStart, err := DecodeLZ77WithOptions(bitStream, 10, 4, DecodeOptions{OffsetMode: FromStart})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromEnd.Output != "abccc" || fromEnd.SkippedRefs != 1 {
+		t.Errorf("FromEnd: got %q with %d skipped, want %q with 1", fromEnd.Output, fromEnd.SkippedRefs, "abccc")
+	}
+	if fromStart.Output != "abcbca" || fromStart.SkippedRefs != 0 {
+		t.Errorf("FromStart: got %q with %d skipped, want %q with none", fromStart.Output, fromStart.SkippedRefs, "abcbca")
+	}
+
+	// Once the window is full, FromStart counts from its oldest symbol
+	oldest := literalBits('a') + literalBits('b') + literalBits('c') + referenceBits(0, 1)
+	windowed, err := DecodeLZ77WithOptions(oldest, 10, 4, DecodeOptions{OffsetMode: FromStart, WindowSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if windowed.Output != "abcb" {
+		t.Errorf("FromStart with a 2-symbol window: got %q, want %q", windowed.Output, "abcb")
+	}
+}
+
+func TestBinaryGlyphAlphabet(t *testing.T) {
+	// Literals are a flag and one bit selecting one of two glyphs; the
+	// reference repeats the last four glyphs twice
+	opts := DecodeOptions{LiteralBits: 1, Alphabet: []rune("ab")}
+	bitStream := "00" + "01" + "01" + "00" + "1" + "0000000100" + "1000"
+	result, err := DecodeLZ77WithOptions(bitStream, 10, 4, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "abbaabbaabba" || result.FilteredLiterals != 0 {
+		t.Errorf("got %q with %d filtered, want %q with none", result.Output, result.FilteredLiterals, "abbaabbaabba")
+	}
+
+	_, err = DecodeLZ77WithOptions(bitStream, 10, 4, DecodeOptions{LiteralBits: 1, Alphabet: []rune("abc")})
+	if err == nil || !strings.Contains(err.Error(), "does not fit in 1 literal bits") {
+		t.Errorf("error = %v, want a 3-symbol alphabet rejected for 1-bit literals", err)
+	}
+}
+
+func TestUnicodeLiterals(t *testing.T) {
+	// 16-bit literals as code points: Greek letters survive with
+	// PrintableUnicode, and a reference copies them like any symbol
+	var b strings.Builder
+	for _, r := range "αβγ" {
+		b.WriteByte('0')
+		writeBits(&b, int(r), 16)
+	}
+	b.WriteString("1" + "0000000011" + "0011")
+	opts := DecodeOptions{LiteralBits: 16, Accept: PrintableUnicode}
+	result, err := DecodeLZ77WithOptions(b.String(), 10, 4, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "αβγαβγ" {
+		t.Errorf("got %q, want %q", result.Output, "αβγαβγ")
+	}
+
+	// A 2-bit lookup table into a small glyph alphabet
+	glyphs := []rune("⊙♀☿♄")
+	opts = DecodeOptions{LiteralBits: 2, Alphabet: glyphs}
+	result, err = DecodeLZ77WithOptions("000"+"011"+"010"+"001", 10, 4, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output != "⊙♄☿♀" {
+		t.Errorf("got %q, want %q", result.Output, "⊙♄☿♀")
+	}
+	if ShannonEntropy(result.Output) != 2 {
+		t.Errorf("entropy = %v, want 2 bits over four distinct glyphs", ShannonEntropy(result.Output))
+	}
+}
+
+func TestValidateLZ77(t *testing.T) {
+	valid, err := EncodeLZ77(benchText(1<<10), 10, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ab := literalBits('a') + literalBits('b')
+	tests := []struct {
+		name       string
+		bitStream  string
+		offsetBits int
+		wantReason string // Empty for a valid stream
+	}{
+		{name: "encoded text", bitStream: valid, offsetBits: 10},
+		{name: "empty", bitStream: "", offsetBits: 10},
+		{name: "bad width", bitStream: ab, offsetBits: 0, wantReason: "offsetBits must be between 1 and 30, got 0"},
+		{name: "bad flag", bitStream: ab + "x", offsetBits: 10, wantReason: `invalid bitstream character 'x' at position 18`},
+		{name: "bad field", bitStream: ab + "1" + "000x000001" + "0010", offsetBits: 10, wantReason: `invalid bitstream character 'x' at position 22`},
+		{name: "truncated literal", bitStream: ab + "0101", offsetBits: 10, wantReason: "incomplete literal at position 19"},
+		{name: "truncated reference", bitStream: ab + "1000", offsetBits: 10, wantReason: "incomplete back-reference at position 19"},
+		{name: "offset 0", bitStream: ab + referenceBits(0, 2), offsetBits: 10, wantReason: "invalid back-reference (offset 0, length 2, window 2) at position 18"},
+		{name: "offset past window", bitStream: ab + referenceBits(3, 2), offsetBits: 10, wantReason: "invalid back-reference (offset 3, length 2, window 2) at position 18"},
+		{name: "length 0", bitStream: ab + referenceBits(1, 0), offsetBits: 10, wantReason: "invalid back-reference (offset 1, length 0, window 2) at position 18"},
+		// A filtered literal adds nothing to the window
+		{name: "after filtered literal", bitStream: literalBits(7) + referenceBits(1, 1), offsetBits: 10, wantReason: "invalid back-reference (offset 1, length 1, window 0) at position 9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, reason := ValidateLZ77(tt.bitStream, tt.offsetBits, 4)
+			if ok != (tt.wantReason == "") || reason != tt.wantReason {
+				t.Errorf("ValidateLZ77 = %t, %q, want %t, %q", ok, reason, tt.wantReason == "", tt.wantReason)
+			}
+			// A valid stream decodes strictly without error, and the
+			// reason matches the strict decode's error
+			if tt.offsetBits < 1 {
+				return
+			}
+			_, err := DecodeLZ77WithOptions(tt.bitStream, tt.offsetBits, 4, DecodeOptions{Strict: true})
+			if (err == nil) != ok || (err != nil && err.Error() != reason) {
+				t.Errorf("strict decode error %v disagrees with ValidateLZ77 %t, %q", err, ok, reason)
+			}
+		})
+	}
+}
+
+func TestMaxOutput(t *testing.T) {
+	// Each overlapping reference repeats the last symbol 15 times, so a
+	// short stream expands far past a small limit
+	bitStream := literalBits('a') + strings.Repeat(referenceBits(1, 15), 100)
+
+	unlimited, err := DecodeLZ77WithOptions(bitStream, 10, 4, DecodeOptions{})
+	if err != nil || len(unlimited.Output) != 1501 {
+		t.Fatalf("unlimited: %d symbols, %v, want 1501", len(unlimited.Output), err)
+	}
+
+	result, err := DecodeLZ77WithOptions(bitStream, 10, 4, DecodeOptions{MaxOutput: 40})
+	if !errors.Is(err, ErrOutputLimit) {
+		t.Fatalf("error = %v, want ErrOutputLimit", err)
+	}
+	// The reference that would cross the limit is not applied
+	if result.Output != strings.Repeat("a", 31) || result.ReferenceCount != 2 {
+		t.Errorf("got %d symbols from %d references, want 31 from 2", len(result.Output), result.ReferenceCount)
+	}
+	if !strings.Contains(err.Error(), "at position 39") {
+		t.Errorf("error = %v, want the third reference's position 39", err)
+	}
+
+	// Exactly reaching the limit is allowed
+	if _, err := DecodeLZ77WithOptions(bitStream[:9+2*15], 10, 4, DecodeOptions{MaxOutput: 31}); err != nil {
+		t.Errorf("output of exactly MaxOutput symbols: %v", err)
+	}
+}
+
+func TestDecodeLZ77RunesSpans(t *testing.T) {
+	bitStream, err := EncodeLZ77(phaseText, 10, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runes, err := DecodeLZ77Runes(bitStream, 10, 4, DecodeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var output []rune
+	end := 0
+	references := 0
+	for i, r := range runes {
+		output = append(output, r.Rune)
+		// Runes of one command share a span; the next command starts
+		// where the previous one ended
+		if i > 0 && r.BitStart == runes[i-1].BitStart {
+			if r.BitEnd != runes[i-1].BitEnd || !r.FromReference {
+				t.Fatalf("rune %d shares a start with rune %d but not its command: %+v, %+v", i, i-1, runes[i-1], r)
+			}
+			continue
+		}
+		if r.BitStart != end {
+			t.Fatalf("rune %d starts at bit %d, want %d", i, r.BitStart, end)
+		}
+		wantWidth := 9
+		if r.FromReference {
+			wantWidth = 15
+			references++
+		}
+		if r.BitEnd-r.BitStart != wantWidth {
+			t.Errorf("rune %d spans %d bits, want %d", i, r.BitEnd-r.BitStart, wantWidth)
+		}
+		end = r.BitEnd
+	}
+	if end != len(bitStream) {
+		t.Errorf("spans end at bit %d, want the whole %d-bit stream", end, len(bitStream))
+	}
+	if string(output) != phaseText || references == 0 {
+		t.Errorf("runes spell %q with %d references, want %q with some", string(output), references, phaseText)
+	}
+}
@@ -0,0 +1,114 @@
+package voynich
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DecodeLZ78 decompresses a bitstream of LZ78 (dictionary-index,
+// next-symbol) pairs. Each pair is an indexBits-wide index followed by a
+// literalBits-wide symbol code, both MSB-first. The pair emits the indexed
+// dictionary phrase followed by the symbol, and that new phrase is appended
+// to the dictionary. Index 0 is the empty prefix.
+//
+// Symbol codes are read as ASCII and, as in DecodeLZ77, only the printable
+// range 32..126 is kept; a dropped symbol still adds its prefix to the
+// dictionary so later indices stay aligned. An index that points past the
+// dictionary, or a character other than '0' or '1', is an error. On error
+// the output decoded so far is returned.
+func DecodeLZ78(bitStream string, indexBits, literalBits int) (string, error) {
+	if indexBits < 1 || indexBits > maxFieldBits {
+		return "", fmt.Errorf("indexBits must be between 1 and %d, got %d", maxFieldBits, indexBits)
+	}
+	if literalBits < 1 || literalBits > maxFieldBits {
+		return "", fmt.Errorf("literalBits must be between 1 and %d, got %d", maxFieldBits, literalBits)
+	}
+
+	var output strings.Builder
+	dictionary := []string{""} // Index 0 is the empty prefix
+	position := 0
+
+	for position < len(bitStream) {
+		if position+indexBits+literalBits > len(bitStream) {
+			return output.String(), fmt.Errorf("incomplete pair at position %d", position)
+		}
+		if i := invalidBit(bitStream[position : position+indexBits+literalBits]); i >= 0 {
+			return output.String(), fmt.Errorf("invalid bitstream character %q at position %d",
+				bitStream[position+i], position+i)
+		}
+
+		// Read dictionary index
+		index := readBits(bitStream[position:position+indexBits], MSBFirst)
+		if index >= len(dictionary) {
+			return output.String(), fmt.Errorf("index %d at position %d exceeds dictionary size %d",
+				index, position, len(dictionary))
+		}
+		position += indexBits
+
+		// Read next symbol
+		charCode := readBits(bitStream[position:position+literalBits], MSBFirst)
+		position += literalBits
+
+		phrase := dictionary[index]
+		if charCode >= 32 && charCode <= 126 {
+			phrase += string(rune(charCode))
+		}
+
+		output.WriteString(phrase)
+		dictionary = append(dictionary, phrase)
+	}
+
+	return output.String(), nil
+}
+
+// EncodeLZ78 compresses printable ASCII text into the pair format read by
+// DecodeLZ78. At each step it extends the longest phrase already in the
+// dictionary by one character. Only the first 2^indexBits dictionary
+// entries can be referenced; later phrases are still implied by the
+// decoder's dictionary but are never used.
+func EncodeLZ78(text string, indexBits, literalBits int) (string, error) {
+	if indexBits < 1 || indexBits > maxFieldBits {
+		return "", fmt.Errorf("indexBits must be between 1 and %d, got %d", maxFieldBits, indexBits)
+	}
+	if literalBits < 1 || literalBits > maxFieldBits {
+		return "", fmt.Errorf("literalBits must be between 1 and %d, got %d", maxFieldBits, literalBits)
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] < 32 || text[i] > 126 {
+			return "", fmt.Errorf("character %q at index %d is not printable ASCII", text[i], i)
+		}
+		if int(text[i]) >= 1<<literalBits {
+			return "", fmt.Errorf("character %q at index %d does not fit in %d literal bits", text[i], i, literalBits)
+		}
+	}
+
+	var bitStream strings.Builder
+	dictionary := map[string]int{"": 0}
+	nextIndex := 1
+	maxIndex := 1<<indexBits - 1
+	position := 0
+
+	for position < len(text) {
+		// Find the longest dictionary phrase that leaves a character to
+		// follow it; the dictionary is prefix-closed, so extend greedily.
+		length := 0
+		for position+length+1 < len(text) {
+			if _, ok := dictionary[text[position:position+length+1]]; !ok {
+				break
+			}
+			length++
+		}
+
+		prefix := text[position : position+length]
+		writeBits(&bitStream, dictionary[prefix], indexBits)
+		writeBits(&bitStream, int(text[position+length]), literalBits)
+
+		if nextIndex <= maxIndex {
+			dictionary[text[position:position+length+1]] = nextIndex
+		}
+		nextIndex++
+		position += length + 1
+	}
+
+	return bitStream.String(), nil
+}
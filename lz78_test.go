@@ -0,0 +1,81 @@
+package voynich
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncodeLZ78RoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		indexBits int
+	}{
+		{name: "empty", text: "", indexBits: 8},
+		{name: "single", text: "a", indexBits: 8},
+		{name: "repetitive", text: strings.Repeat("abab", 40), indexBits: 8},
+		// Two index bits fill the dictionary after three phrases
+		{name: "full dictionary", text: strings.Repeat("abcab", 20), indexBits: 2},
+		{name: "english", text: benchText(4 << 10), indexBits: 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bitStream, err := EncodeLZ78(tt.text, tt.indexBits, 7)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := DecodeLZ78(bitStream, tt.indexBits, 7)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.text {
+				t.Errorf("round trip gave %q, want %q", got, tt.text)
+			}
+		})
+	}
+}
+
+func TestDecodeLZ78IndexPastDictionary(t *testing.T) {
+	// The first pair may only use index 0; index 1 does not exist yet
+	got, err := DecodeLZ78("01"+"1000001", 2, 7)
+	if err == nil || err.Error() != "index 1 at position 0 exceeds dictionary size 1" {
+		t.Errorf("error = %v, want index 1 rejected", err)
+	}
+	if got != "" {
+		t.Errorf("output = %q, want none", got)
+	}
+
+	// After one pair index 1 is valid and index 2 is not
+	got, err = DecodeLZ78("00"+"1000001"+"01"+"1000010"+"11"+"1000011", 2, 7)
+	if err == nil || err.Error() != "index 3 at position 18 exceeds dictionary size 3" {
+		t.Errorf("error = %v, want index 3 rejected", err)
+	}
+	if got != "AAB" {
+		t.Errorf("output = %q, want %q", got, "AAB")
+	}
+}
+
+func TestDecodeLZ78RejectsNonBitCharacters(t *testing.T) {
+	tests := []struct {
+		name      string
+		bitStream string
+		want      string
+		wantErr   string
+	}{
+		{name: "in index", bitStream: "x01000001", wantErr: `invalid bitstream character 'x' at position 0`},
+		{name: "in symbol", bitStream: "0x1000001", wantErr: `invalid bitstream character 'x' at position 1`},
+		{name: "after a pair", bitStream: "001000001" + "10100000 ", want: "A", wantErr: `invalid bitstream character ' ' at position 17`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeLZ78(tt.bitStream, 1, 8)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package voynich
+
+import (
+	"bytes"
+	"compress/flate"
+)
+
+// NCD computes the normalized compression distance between two texts,
+// (C(ab) - min(C(a), C(b))) / max(C(a), C(b)), where C is the compressed
+// size. It approximates how much information the texts share: near 0 for
+// texts that largely repeat each other and near 1 for unrelated ones,
+// though real compressors can push it slightly outside [0, 1]. C uses
+// DEFLATE rather than EncodeLZ77 so that any text, including line breaks
+// and non-ASCII glyphs, can be compared. Two empty texts have distance 0.
+func NCD(a, b string) float64 {
+	ca, cb := compressedSize(a), compressedSize(b)
+	cab := compressedSize(a + b)
+
+	minSize, maxSize := ca, cb
+	if minSize > maxSize {
+		minSize, maxSize = maxSize, minSize
+	}
+	if maxSize == 0 {
+		return 0
+	}
+	return float64(cab-minSize) / float64(maxSize)
+}
+
+// compressedSize returns the length in bytes of data compressed with
+// DEFLATE at the best compression level, or 0 for empty data.
+func compressedSize(data string) int {
+	if data == "" {
+		return 0
+	}
+
+	var buf bytes.Buffer
+	writer, _ := flate.NewWriter(&buf, flate.BestCompression) // Only fails for an invalid level
+	writer.Write([]byte(data))
+	writer.Close()
+	return buf.Len()
+}
@@ -0,0 +1,27 @@
+package voynich
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestNCD(t *testing.T) {
+	text := benchText(4 << 10)
+	if got := NCD(text, text); got > 0.1 {
+		t.Errorf("NCD of identical texts = %v, want near 0", got)
+	}
+
+	// Random letters share nothing with the English-like text
+	source := rand.New(rand.NewSource(1))
+	random := make([]byte, len(text))
+	for i := range random {
+		random[i] = byte('a' + source.Intn(26))
+	}
+	if got := NCD(text, string(random)); got < 0.8 {
+		t.Errorf("NCD of unrelated texts = %v, want near 1", got)
+	}
+
+	if got := NCD("", ""); got != 0 {
+		t.Errorf("NCD of two empty texts = %v, want 0", got)
+	}
+}
@@ -0,0 +1,193 @@
+package voynich
+
+import "sort"
+
+// MaxRunUnit is the longest repeating unit, in characters, that DetectRuns
+// looks for. It comfortably covers repeated Voynich words such as "daiin ".
+const MaxRunUnit = 16
+
+// Run is a stretch of text made of consecutive copies of a repeating unit.
+type Run struct {
+	Unit  string // The repeated substring
+	Start int    // Character (rune) offset of the first copy
+	Count int    // Number of complete consecutive copies, at least 2
+}
+
+// DetectRuns finds maximal runs of a repeating unit of up to MaxRunUnit
+// characters whose complete copies cover at least minLen characters. Only
+// primitive units are reported ("ab" rather than "abab"), and each run is
+// reported once rather than once per rotation of its unit. Runs of
+// different units may overlap. Results are ordered by start offset and then
+// unit length.
+func DetectRuns(data string, minLen int) []Run {
+	symbols := []rune(data)
+	var runs []Run
+
+	for unitLen := 1; unitLen <= MaxRunUnit && 2*unitLen <= len(symbols); unitLen++ {
+		i := 0
+		for i+2*unitLen <= len(symbols) {
+			// Extend while each character repeats the one a unit earlier
+			end := i + unitLen
+			for end < len(symbols) && symbols[end] == symbols[end-unitLen] {
+				end++
+			}
+
+			count := (end - i) / unitLen
+			if count < 2 {
+				i++
+				continue
+			}
+
+			unit := symbols[i : i+unitLen]
+			if count*unitLen >= minLen && isPrimitive(unit) {
+				runs = append(runs, Run{Unit: string(unit), Start: i, Count: count})
+			}
+
+			// Skip the rotations of this unit that lie inside the same run
+			i = end - unitLen + 1
+		}
+	}
+
+	sort.SliceStable(runs, func(a, b int) bool {
+		if runs[a].Start != runs[b].Start {
+			return runs[a].Start < runs[b].Start
+		}
+		return len([]rune(runs[a].Unit)) < len([]rune(runs[b].Unit))
+	})
+	return runs
+}
+
+// isPrimitive reports whether unit is not itself a repetition of a shorter
+// substring.
+func isPrimitive(unit []rune) bool {
+	for period := 1; period < len(unit); period++ {
+		if len(unit)%period != 0 {
+			continue
+		}
+		periodic := true
+		for i := period; i < len(unit); i++ {
+			if unit[i] != unit[i-period] {
+				periodic = false
+				break
+			}
+		}
+		if periodic {
+			return false
+		}
+	}
+	return true
+}
+
+// Autocorrelation compares the character sequence with shifted copies of
+// itself, treating characters as categorical. Element k-1 of the result is
+// the fraction of positions i for which data[i] == data[i+k], for lags k
+// from 1 to maxLag. Peaks at a lag reveal periodic structure that
+// frequency statistics miss. Lags with no overlapping positions give 0.
+func Autocorrelation(data string, maxLag int) []float64 {
+	if maxLag < 1 {
+		return nil
+	}
+
+	symbols := []rune(data)
+	correlations := make([]float64, maxLag)
+	for lag := 1; lag <= maxLag; lag++ {
+		overlap := len(symbols) - lag
+		if overlap <= 0 {
+			continue
+		}
+
+		matches := 0
+		for i := 0; i < overlap; i++ {
+			if symbols[i] == symbols[i+lag] {
+				matches++
+			}
+		}
+		correlations[lag-1] = float64(matches) / float64(overlap)
+	}
+	return correlations
+}
+
+// Kasiski performs a Kasiski examination: it finds every substring of
+// minSeqLen characters that occurs more than once and returns a histogram
+// of the distances, in characters, between each pair of successive
+// occurrences. Under a periodic key, repeats of the same plaintext line up
+// at multiples of the key length, so the common divisors of the most
+// frequent distances suggest the period. Longer repeats contribute once per
+// minSeqLen-character window they contain. It returns an empty histogram
+// when minSeqLen is less than 1.
+func Kasiski(data string, minSeqLen int) map[int]int {
+	distances := make(map[int]int)
+	symbols := []rune(data)
+	if minSeqLen < 1 {
+		return distances
+	}
+
+	lastSeen := make(map[string]int)
+	for i := 0; i+minSeqLen <= len(symbols); i++ {
+		sequence := string(symbols[i : i+minSeqLen])
+		if prev, ok := lastSeen[sequence]; ok {
+			distances[i-prev]++
+		}
+		lastSeen[sequence] = i
+	}
+	return distances
+}
+
+// NGramCount is an n-gram together with its number of occurrences.
+type NGramCount struct {
+	NGram string
+	Count int
+}
+
+// TopNGrams returns the k most frequent overlapping n-character sequences
+// of data, most frequent first, with ties broken by code point order so
+// the result is deterministic. A k of 0 or less returns every n-gram. It
+// returns nil when n is less than 1 or data is shorter than n characters.
+func TopNGrams(data string, n, k int) []NGramCount {
+	symbols := []rune(data)
+	if n < 1 || len(symbols) < n {
+		return nil
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i+n <= len(symbols); i++ {
+		counts[string(symbols[i:i+n])]++
+	}
+
+	ngrams := make([]NGramCount, 0, len(counts))
+	for ngram, count := range counts {
+		ngrams = append(ngrams, NGramCount{NGram: ngram, Count: count})
+	}
+	sort.Slice(ngrams, func(i, j int) bool {
+		if ngrams[i].Count != ngrams[j].Count {
+			return ngrams[i].Count > ngrams[j].Count
+		}
+		return ngrams[i].NGram < ngrams[j].NGram
+	})
+
+	if k > 0 && k < len(ngrams) {
+		ngrams = ngrams[:k]
+	}
+	return ngrams
+}
+
+// MirrorScore measures how closely a text reads the same backwards: the
+// fraction of positions i at which the character equals the one at the
+// mirrored position n-1-i. A palindrome scores 1, while text over an
+// alphabet of k roughly equally common symbols scores about 1/k. The
+// comparison is positional, so it runs in linear time but does not credit
+// mirrored passages that are shifted. Empty input returns 0.
+func MirrorScore(data string) float64 {
+	symbols := []rune(data)
+	if len(symbols) == 0 {
+		return 0
+	}
+
+	matches := 0
+	for i := range symbols {
+		if symbols[i] == symbols[len(symbols)-1-i] {
+			matches++
+		}
+	}
+	return float64(matches) / float64(len(symbols))
+}
@@ -0,0 +1,165 @@
+package voynich
+
+import (
+	"maps"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetectRuns(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		minLen int
+		want   []Run
+	}{
+		{name: "empty", data: "", minLen: 2, want: nil},
+		{name: "no runs", data: "abcdef", minLen: 2, want: nil},
+		{
+			// The run starts at the first repeated character, the space
+			name:   "repeated word",
+			data:   "qokeedy daiin daiin daiin chol",
+			minLen: 12,
+			want:   []Run{{Unit: " daiin", Start: 7, Count: 3}},
+		},
+		{
+			// The "a" run ends where the "ab" run begins, sharing one "a"
+			name:   "overlapping runs",
+			data:   "aaabababab",
+			minLen: 3,
+			want:   []Run{{Unit: "a", Start: 0, Count: 3}, {Unit: "ab", Start: 2, Count: 4}},
+		},
+		{
+			// "abab" repeats too but is not primitive, and the rotation
+			// "ba" of the same run is not reported again
+			name:   "primitive unit once",
+			data:   "xabababababy",
+			minLen: 4,
+			want:   []Run{{Unit: "ab", Start: 1, Count: 5}},
+		},
+		{
+			name:   "below minLen",
+			data:   "xyxy aa",
+			minLen: 5,
+			want:   nil,
+		},
+		{
+			name:   "partial copy not counted",
+			data:   "abcabcab",
+			minLen: 6,
+			want:   []Run{{Unit: "abc", Start: 0, Count: 2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectRuns(tt.data, tt.minLen); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectRuns(%q, %d) = %+v, want %+v", tt.data, tt.minLen, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutocorrelationPeriod3(t *testing.T) {
+	correlations := Autocorrelation(strings.Repeat("abc", 20), 7)
+	want := []float64{0, 0, 1, 0, 0, 1, 0}
+	if !reflect.DeepEqual(correlations, want) {
+		t.Errorf("Autocorrelation = %v, want %v", correlations, want)
+	}
+
+	// A noisy period still peaks at its multiples
+	noisy := []byte(strings.Repeat("qok", 50))
+	for i := 0; i < len(noisy); i += 7 {
+		noisy[i] = 'x'
+	}
+	correlations = Autocorrelation(string(noisy), 6)
+	for lag := 1; lag <= 6; lag++ {
+		isPeak := lag%3 == 0
+		if got := correlations[lag-1]; isPeak != (got > 0.5) {
+			t.Errorf("lag %d: correlation %v, want a peak only at multiples of 3", lag, got)
+		}
+	}
+
+	if got := Autocorrelation("ab", 3); !reflect.DeepEqual(got, []float64{0, 0, 0}) {
+		t.Errorf("Autocorrelation past the text = %v, want zeros", got)
+	}
+	if got := Autocorrelation("abc", 0); got != nil {
+		t.Errorf("Autocorrelation(maxLag 0) = %v, want nil", got)
+	}
+}
+
+func TestKasiski(t *testing.T) {
+	// "secret" planted 21 characters apart in filler with no repeated
+	// trigram: each of its four trigrams repeats at distance 21
+	data := "abc" + "secret" + "ABCDEFGHIJKLMNO" + "secret" + "xyz"
+	want := map[int]int{21: 4}
+	if got := Kasiski(data, 3); !maps.Equal(got, want) {
+		t.Errorf("Kasiski = %v, want %v", got, want)
+	}
+	// The whole planted word only repeats once at length 6
+	if got := Kasiski(data, 6); !maps.Equal(got, map[int]int{21: 1}) {
+		t.Errorf("Kasiski(minSeqLen 6) = %v, want one distance of 21", got)
+	}
+
+	// Successive occurrences are measured pairwise
+	if got := Kasiski("abXabYYab", 2); !maps.Equal(got, map[int]int{3: 1, 4: 1}) {
+		t.Errorf("Kasiski of three occurrences = %v, want distances 3 and 4", got)
+	}
+	if got := Kasiski("abab", 0); len(got) != 0 {
+		t.Errorf("Kasiski(minSeqLen 0) = %v, want empty", got)
+	}
+}
+
+func TestTopNGrams(t *testing.T) {
+	tests := []struct {
+		data string
+		n, k int
+		want []NGramCount
+	}{
+		{"banana", 2, 0, []NGramCount{{"an", 2}, {"na", 2}, {"ba", 1}}},
+		{"banana", 2, 2, []NGramCount{{"an", 2}, {"na", 2}}},
+		{"banana", 3, 1, []NGramCount{{"ana", 2}}},
+		// Equal counts fall back to code point order
+		{"abcabd", 3, 0, []NGramCount{{"abc", 1}, {"abd", 1}, {"bca", 1}, {"cab", 1}}},
+		{"αβαβ", 2, 0, []NGramCount{{"αβ", 2}, {"βα", 1}}},
+		{"banana", 6, 0, []NGramCount{{"banana", 1}}},
+		{"banana", 7, 0, nil},
+		{"banana", 0, 0, nil},
+	}
+	for _, tt := range tests {
+		if got := TopNGrams(tt.data, tt.n, tt.k); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("TopNGrams(%q, %d, %d) = %v, want %v", tt.data, tt.n, tt.k, got, tt.want)
+		}
+	}
+}
+
+func TestMirrorScore(t *testing.T) {
+	tests := []struct {
+		data string
+		want float64
+	}{
+		{"racecar", 1},
+		{"abba", 1},
+		{"a", 1},
+		{"abcd", 0},
+		{"abca", 0.5}, // Only the ends match
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := MirrorScore(tt.data); got != tt.want {
+			t.Errorf("MirrorScore(%q) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+
+	// Random symbols over four letters score about 1/4
+	source := rand.New(rand.NewSource(1))
+	random := make([]byte, 10000)
+	for i := range random {
+		random[i] = "abcd"[source.Intn(4)]
+	}
+	if got := MirrorScore(string(random)); math.Abs(got-0.25) > 0.02 {
+		t.Errorf("MirrorScore of random text = %v, want about 0.25", got)
+	}
+}
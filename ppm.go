@@ -0,0 +1,95 @@
+package voynich
+
+import "math"
+
+// PPMEntropy estimates the entropy of data in bits/symbol as the average
+// code length an adaptive PPM (prediction by partial matching) model would
+// need for it. The text is read left to right and each symbol is predicted
+// from the contexts of the preceding maxOrder symbols down to none, using
+// method C escapes and exclusion: a context that has not seen the symbol
+// pays an escape probability and passes to the next shorter context, which
+// ignores the symbols already ruled out. A symbol new to every context is
+// coded uniformly over the distinct characters of data not yet excluded.
+// The context counts are updated after each symbol and start empty; the
+// only thing taken from the whole text in advance is the size of its
+// alphabet, which a real coder would have to transmit up front.
+//
+// Unlike EntropyRate, which measures a model fitted to the whole text,
+// this is close to a true compression estimate: language-like text scores well
+// below random symbols over the same alphabet, and it is a strong
+// statistic for ranking candidate decodes. A negative maxOrder is treated
+// as 0, and empty input returns 0.
+func PPMEntropy(data string, maxOrder int) float64 {
+	symbols := []rune(data)
+	if len(symbols) == 0 {
+		return 0
+	}
+	if maxOrder < 0 {
+		maxOrder = 0
+	}
+	alphabetSize := len(CharCounts(data))
+
+	// contexts[k] maps each context of k symbols to the counts of the
+	// symbols seen after it
+	contexts := make([]map[string]map[rune]int, maxOrder+1)
+	for k := range contexts {
+		contexts[k] = make(map[string]map[rune]int)
+	}
+
+	var bits float64
+	excluded := make(map[rune]bool)
+	for i, symbol := range symbols {
+		highest := maxOrder
+		if highest > i {
+			highest = i
+		}
+
+		for char := range excluded {
+			delete(excluded, char)
+		}
+		probability := 1.0
+		found := false
+		for k := highest; k >= 0 && !found; k-- {
+			counts := contexts[k][string(symbols[i-k:i])]
+
+			// Method C: the escape count is the number of distinct symbols
+			// seen in the context, after exclusion
+			total, distinct := 0, 0
+			for char, count := range counts {
+				if !excluded[char] {
+					total += count
+					distinct++
+				}
+			}
+			if distinct == 0 {
+				continue // Nothing to predict from; escaping is free
+			}
+
+			if count := counts[symbol]; count > 0 && !excluded[symbol] {
+				probability *= float64(count) / float64(total+distinct)
+				found = true
+			} else {
+				probability *= float64(distinct) / float64(total+distinct)
+				for char := range counts {
+					excluded[char] = true
+				}
+			}
+		}
+		if !found {
+			probability /= float64(alphabetSize - len(excluded))
+		}
+		bits -= math.Log2(probability)
+
+		// Update every context order with the symbol just coded
+		for k := 0; k <= highest; k++ {
+			context := string(symbols[i-k : i])
+			counts := contexts[k][context]
+			if counts == nil {
+				counts = make(map[rune]int)
+				contexts[k][context] = counts
+			}
+			counts[symbol]++
+		}
+	}
+	return bits / float64(len(symbols))
+}
@@ -0,0 +1,48 @@
+package voynich
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPPMEntropyEnglishBelowRandom(t *testing.T) {
+	english := strings.Repeat("the quick brown fox jumps over the lazy dog and then "+
+		"the dog sleeps in the sun while the fox runs away ", 20)
+
+	// Random text over the same 27-symbol alphabet and of the same length
+	alphabet := []rune("abcdefghijklmnopqrstuvwxyz ")
+	bits := RandomBitStream(len(english)*8, 1)
+	var random strings.Builder
+	for i := 0; i < len(english); i++ {
+		random.WriteRune(alphabet[readBits(bits[i*8:i*8+8], MSBFirst)%len(alphabet)])
+	}
+
+	for _, order := range []int{0, 1, 2, 3, 5} {
+		englishBits := PPMEntropy(english, order)
+		randomBits := PPMEntropy(random.String(), order)
+		if englishBits >= randomBits {
+			t.Errorf("order %d: English %.4f bits/symbol, random %.4f; want English lower",
+				order, englishBits, randomBits)
+		}
+	}
+
+	// Higher orders find the repetition
+	if low, high := PPMEntropy(english, 0), PPMEntropy(english, 3); high >= low/2 {
+		t.Errorf("order 3 gives %.4f bits/symbol, want well below order 0's %.4f", high, low)
+	}
+}
+
+func TestPPMEntropyEdgeCases(t *testing.T) {
+	if got := PPMEntropy("", 3); got != 0 {
+		t.Errorf("PPMEntropy(\"\") = %v, want 0", got)
+	}
+	// The first symbol of a one-symbol alphabet is free; each later one
+	// is the single symbol of a new context at probability 1/2
+	if got := PPMEntropy("aaaa", 2); got != 0.75 {
+		t.Errorf("PPMEntropy(\"aaaa\", 2) = %v, want 0.75", got)
+	}
+	// "ab": a is one of two symbols and b escapes to the only one left
+	if got := PPMEntropy("ab", -1); got != 1 {
+		t.Errorf("PPMEntropy(\"ab\", -1) = %v, want 1", got)
+	}
+}
@@ -0,0 +1,95 @@
+package voynich
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// ScoreWeights sets how much each metric contributes to ScoreCandidate.
+// Lower scores are better: entropy and conditional entropy add to the
+// score, while index of coincidence, Zipf R² and redundancy, which are
+// higher for language-like text, subtract from it.
+type ScoreWeights struct {
+	Entropy            float64
+	ConditionalEntropy float64
+	IndexOfCoincidence float64
+	ZipfR2             float64
+	Redundancy         float64
+}
+
+// DefaultScoreWeights ranks candidates by Shannon entropy alone.
+var DefaultScoreWeights = ScoreWeights{Entropy: 1}
+
+// ScoreCandidate combines several language-likeness metrics of a decoded
+// output into one weighted score, where lower is better. Metrics with a
+// zero weight are not computed.
+func ScoreCandidate(output string, weights ScoreWeights) float64 {
+	var score float64
+	if weights.Entropy != 0 {
+		score += weights.Entropy * ShannonEntropy(output)
+	}
+	if weights.ConditionalEntropy != 0 {
+		score += weights.ConditionalEntropy * ConditionalEntropy(output)
+	}
+	if weights.IndexOfCoincidence != 0 {
+		score -= weights.IndexOfCoincidence * IndexOfCoincidence(output)
+	}
+	if weights.ZipfR2 != 0 {
+		_, r2 := ZipfFit(output)
+		score -= weights.ZipfR2 * r2
+	}
+	if weights.Redundancy != 0 {
+		score -= weights.Redundancy * Redundancy(output)
+	}
+	return score
+}
+
+// RankSweep scores every sweep result with ScoreCandidate and returns them
+// sorted from best to worst. Results that failed to decode are placed
+// after all successful ones. Equal scores are broken by a fixed rule so the
+// best result does not depend on the order of the sweep: the smaller
+// OffsetBits wins, then the smaller LengthBits, then the longer output,
+// then MSBFirst before LSBFirst, then the forward stream before the
+// reversed one. The input slice is not modified.
+func RankSweep(results []SweepResult, weights ScoreWeights) []SweepResult {
+	ranked := make([]SweepResult, len(results))
+	copy(ranked, results)
+	for i := range ranked {
+		ranked[i].Score = ScoreCandidate(ranked[i].Output, weights)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := &ranked[i], &ranked[j]
+		switch {
+		case (a.Err == nil) != (b.Err == nil):
+			return a.Err == nil
+		case a.Score != b.Score:
+			return a.Score < b.Score
+		case a.OffsetBits != b.OffsetBits:
+			return a.OffsetBits < b.OffsetBits
+		case a.LengthBits != b.LengthBits:
+			return a.LengthBits < b.LengthBits
+		}
+		if lengthA, lengthB := utf8.RuneCountInString(a.Output), utf8.RuneCountInString(b.Output); lengthA != lengthB {
+			return lengthA > lengthB
+		}
+		if a.BitOrder != b.BitOrder {
+			return a.BitOrder < b.BitOrder
+		}
+		return !a.Reversed && b.Reversed
+	})
+	return ranked
+}
+
+// CompressionRatio returns the number of decoded characters per input bit,
+// a measure of how much the LZ77 interpretation expanded the stream. A
+// stream of nothing but 8-bit literals decodes to 1/9 character per bit;
+// back-references copying long matches push the ratio well above that.
+// A ratio near the literal-only value suggests the parameters found little
+// repetition. It returns 0 when bitStreamLen is not positive.
+func CompressionRatio(bitStreamLen int, result DecodeResult) float64 {
+	if bitStreamLen <= 0 {
+		return 0
+	}
+	return float64(utf8.RuneCountInString(result.Output)) / float64(bitStreamLen)
+}
@@ -0,0 +1,115 @@
+package voynich
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestRankSweepChangesWithWeights(t *testing.T) {
+	// The alternating output has the higher entropy but is perfectly
+	// predictable from the previous character
+	alternating := SweepResult{OffsetBits: 9, DecodeResult: DecodeResult{Output: "abababab"}}
+	skewed := SweepResult{OffsetBits: 10, DecodeResult: DecodeResult{Output: "aaaaaaab"}}
+	failed := SweepResult{OffsetBits: 8, Err: errors.New("incomplete literal at position 3")}
+	results := []SweepResult{alternating, failed, skewed}
+
+	byEntropy := RankSweep(results, DefaultScoreWeights)
+	if byEntropy[0].Output != skewed.Output {
+		t.Errorf("entropy ranks %q first, want %q", byEntropy[0].Output, skewed.Output)
+	}
+	byConditional := RankSweep(results, ScoreWeights{ConditionalEntropy: 1})
+	if byConditional[0].Output != alternating.Output {
+		t.Errorf("conditional entropy ranks %q first, want %q", byConditional[0].Output, alternating.Output)
+	}
+	for _, ranked := range [][]SweepResult{byEntropy, byConditional} {
+		if ranked[2].Err == nil {
+			t.Errorf("failed result ranked at %+v, want last", ranked)
+		}
+	}
+	if results[0].Output != alternating.Output || results[0].Score != 0 {
+		t.Error("RankSweep modified its input")
+	}
+}
+
+func TestRankSweepBreaksTies(t *testing.T) {
+	// Every output has an entropy of 1 bit, so only the tie-break rule
+	// orders them
+	want := []SweepResult{
+		{OffsetBits: 9, LengthBits: 4, DecodeResult: DecodeResult{Output: "ab"}},
+		{OffsetBits: 10, LengthBits: 3, DecodeResult: DecodeResult{Output: "ab"}},
+		{OffsetBits: 10, LengthBits: 4, DecodeResult: DecodeResult{Output: "abab"}}, // Longer output
+		{OffsetBits: 10, LengthBits: 4, DecodeResult: DecodeResult{Output: "ab"}},
+		{OffsetBits: 10, LengthBits: 4, BitOrder: LSBFirst, DecodeResult: DecodeResult{Output: "ab"}},
+		{OffsetBits: 10, LengthBits: 4, BitOrder: LSBFirst, Reversed: true, DecodeResult: DecodeResult{Output: "ab"}},
+	}
+
+	// The ranking is the same whatever order the sweep produced
+	source := rand.New(rand.NewSource(1))
+	for run := 0; run < 10; run++ {
+		results := append([]SweepResult(nil), want...)
+		source.Shuffle(len(results), func(i, j int) { results[i], results[j] = results[j], results[i] })
+
+		ranked := RankSweep(results, DefaultScoreWeights)
+		for i := range want {
+			got := ranked[i]
+			if got.OffsetBits != want[i].OffsetBits || got.LengthBits != want[i].LengthBits || got.Output != want[i].Output ||
+				got.BitOrder != want[i].BitOrder || got.Reversed != want[i].Reversed {
+				t.Fatalf("run %d: rank %d is %+v, want %+v", run, i, got, want[i])
+			}
+		}
+	}
+}
+
+func TestScoreCandidate(t *testing.T) {
+	text := "the quick brown fox jumps over the lazy dog"
+	if got, want := ScoreCandidate(text, DefaultScoreWeights), ShannonEntropy(text); got != want {
+		t.Errorf("default weights score %v, want the entropy %v", got, want)
+	}
+	if got := ScoreCandidate(text, ScoreWeights{}); got != 0 {
+		t.Errorf("zero weights score %v, want 0", got)
+	}
+
+	// Language-like metrics lower the score
+	weights := ScoreWeights{Entropy: 1, IndexOfCoincidence: 2, Redundancy: 3}
+	want := ShannonEntropy(text) - 2*IndexOfCoincidence(text) - 3*Redundancy(text)
+	if got := ScoreCandidate(text, weights); math.Abs(got-want) > 1e-12 {
+		t.Errorf("weighted score %v, want %v", got, want)
+	}
+}
+
+func TestCompressionRatio(t *testing.T) {
+	literals, err := EncodeLZ77("abcdefghijklmnop", 10, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repeats, err := EncodeLZ77(strings.Repeat("abcd", 40), 10, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	literalRatio := CompressionRatio(len(literals), mustDecode(t, literals))
+	repeatRatio := CompressionRatio(len(repeats), mustDecode(t, repeats))
+	if literalRatio != 1.0/9 {
+		t.Errorf("literal-only ratio = %v, want 1/9", literalRatio)
+	}
+	if repeatRatio < 5*literalRatio {
+		t.Errorf("reference-heavy ratio = %v, want well above the literal-only %v", repeatRatio, literalRatio)
+	}
+	if got := CompressionRatio(0, DecodeResult{Output: "abc"}); got != 0 {
+		t.Errorf("CompressionRatio of an empty stream = %v, want 0", got)
+	}
+}
+
+// mustDecode decodes bitStream with 10-bit offsets and 4-bit lengths and
+// fails the test on error.
+func mustDecode(t *testing.T, bitStream string) DecodeResult {
+	t.Helper()
+	result, err := DecodeLZ77WithOptions(bitStream, 10, 4, DecodeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
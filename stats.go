@@ -0,0 +1,296 @@
+package voynich
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// IndexOfCoincidence computes the probability that two characters drawn
+// without replacement from a string are equal: Σ n_i(n_i-1) / (N(N-1)).
+// Natural-language text scores well above the 1/alphabetSize expected of
+// uniformly random symbols. Strings with fewer than two characters return 0.
+func IndexOfCoincidence(data string) float64 {
+	counts := CharCounts(data)
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	if total < 2 {
+		return 0
+	}
+
+	coincidences := 0
+	for _, count := range counts {
+		coincidences += count * (count - 1)
+	}
+
+	return float64(coincidences) / float64(total*(total-1))
+}
+
+// DefaultChiSquaredFloor is the probability ChiSquared assigns to characters
+// that are missing from the expected distribution.
+const DefaultChiSquaredFloor = 1e-4
+
+// ChiSquared computes Pearson's χ² statistic comparing the character counts
+// of data with an expected probability distribution, together with the
+// degrees of freedom for looking up a p-value. Characters in data that are
+// absent from expected are given DefaultChiSquaredFloor.
+func ChiSquared(data string, expected map[rune]float64) (float64, int) {
+	return ChiSquaredFloor(data, expected, DefaultChiSquaredFloor)
+}
+
+// ChiSquaredFloor is ChiSquared with a configurable floor probability for
+// characters that are absent from (or non-positive in) expected. The
+// expected probabilities are renormalized after flooring, so tables given as
+// percentages or raw counts work as well as true probabilities.
+func ChiSquaredFloor(data string, expected map[rune]float64, floor float64) (float64, int) {
+	observed := CharCounts(data)
+	total := 0
+	for _, count := range observed {
+		total += count
+	}
+	if total == 0 {
+		return 0, 0
+	}
+
+	// Every expected character and every observed character is a category
+	probabilities := make(map[rune]float64)
+	for char, probability := range expected {
+		probabilities[char] = probability
+	}
+	for char := range observed {
+		if _, ok := probabilities[char]; !ok {
+			probabilities[char] = 0
+		}
+	}
+
+	var sum float64
+	for char, probability := range probabilities {
+		if probability <= 0 {
+			probability = floor
+			probabilities[char] = probability
+		}
+		sum += probability
+	}
+	if sum <= 0 {
+		return 0, 0
+	}
+
+	// χ² = Σ (O - E)² / E
+	var statistic float64
+	for char, probability := range probabilities {
+		expectedCount := float64(total) * probability / sum
+		if expectedCount <= 0 {
+			continue
+		}
+		diff := float64(observed[char]) - expectedCount
+		statistic += diff * diff / expectedCount
+	}
+
+	return statistic, len(probabilities) - 1
+}
+
+// CosineSimilarity compares the character frequencies of two texts as
+// vectors over the union of their characters and returns the cosine of the
+// angle between them: 1 for texts with proportional frequencies, such as
+// a text and any shuffle of it, down to 0 for texts with no character in
+// common. Only relative frequencies matter, so texts of different lengths
+// compare fairly. If either text is empty its vector is all zero and has
+// no direction, and the similarity is 0.
+func CosineSimilarity(a, b string) float64 {
+	countsA, countsB := CharCounts(a), CharCounts(b)
+
+	// Characters missing from one text contribute only to its partner's norm
+	var dot, normA, normB float64
+	for char, count := range countsA {
+		dot += float64(count) * float64(countsB[char])
+		normA += float64(count) * float64(count)
+	}
+	for _, count := range countsB {
+		normB += float64(count) * float64(count)
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// BigramMatrix returns the raw bigram transition counts of a string:
+// matrix[a][b] is the number of times character b directly follows a. The
+// counts sum to the number of characters minus one.
+func BigramMatrix(data string) map[rune]map[rune]int {
+	matrix := make(map[rune]map[rune]int)
+	symbols := []rune(data)
+	for i := 1; i < len(symbols); i++ {
+		row := matrix[symbols[i-1]]
+		if row == nil {
+			row = make(map[rune]int)
+			matrix[symbols[i-1]] = row
+		}
+		row[symbols[i]]++
+	}
+	return matrix
+}
+
+// WriteBigramCSV renders a bigram matrix as a CSV transition table. The
+// header row and first column list every character that appears in the
+// matrix, in code point order; each cell holds the count of the column
+// character following the row character, with 0 for absent transitions.
+func WriteBigramCSV(w io.Writer, matrix map[rune]map[rune]int) error {
+	// Collect the characters appearing on either side of a transition
+	seen := make(map[rune]bool)
+	for from, row := range matrix {
+		seen[from] = true
+		for to := range row {
+			seen[to] = true
+		}
+	}
+	symbols := make([]rune, 0, len(seen))
+	for symbol := range seen {
+		symbols = append(symbols, symbol)
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i] < symbols[j] })
+
+	writer := csv.NewWriter(w)
+	header := make([]string, len(symbols)+1)
+	for i, symbol := range symbols {
+		header[i+1] = string(symbol)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, from := range symbols {
+		row := make([]string, len(symbols)+1)
+		row[0] = string(from)
+		for i, to := range symbols {
+			row[i+1] = strconv.Itoa(matrix[from][to])
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// AlphabetSize returns the number of distinct characters in a string. An
+// implausibly large alphabet flags a bad decode before entropy is examined.
+func AlphabetSize(data string) int {
+	return len(CharCounts(data))
+}
+
+// DistinctRunes returns the distinct characters of a string in code point
+// order.
+func DistinctRunes(data string) []rune {
+	counts := CharCounts(data)
+	runes := make([]rune, 0, len(counts))
+	for char := range counts {
+		runes = append(runes, char)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	return runes
+}
+
+// AggregateEntropy computes the Shannon entropy of each text, such as the
+// folios of one manuscript section, and returns the mean and population
+// standard deviation of the values. An empty slice returns 0, 0.
+func AggregateEntropy(texts []string) (mean, stddev float64) {
+	return aggregate(texts, ShannonEntropy)
+}
+
+// AggregateIndexOfCoincidence is AggregateEntropy for IndexOfCoincidence.
+func AggregateIndexOfCoincidence(texts []string) (mean, stddev float64) {
+	return aggregate(texts, IndexOfCoincidence)
+}
+
+// AggregateConditionalEntropy is AggregateEntropy for ConditionalEntropy.
+func AggregateConditionalEntropy(texts []string) (mean, stddev float64) {
+	return aggregate(texts, ConditionalEntropy)
+}
+
+// aggregate returns the mean and population standard deviation of metric
+// over texts.
+func aggregate(texts []string, metric func(string) float64) (mean, stddev float64) {
+	if len(texts) == 0 {
+		return 0, 0
+	}
+
+	values := make([]float64, len(texts))
+	for i, text := range texts {
+		values[i] = metric(text)
+		mean += values[i]
+	}
+	mean /= float64(len(values))
+
+	var variance float64
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+// RenderHistogram draws a character histogram, such as one returned by
+// CharCounts, as a text bar chart with one line per character, most
+// frequent first and ties in code point order. Each line holds the
+// character, a bar of '#' scaled so the most frequent character spans
+// width columns, and the count. Every non-zero count gets at least one
+// '#'. Whitespace and other invisible characters are labeled by code point,
+// as in U+0020. Characters with non-positive counts are omitted, and a
+// width below 1 is treated as 1.
+func RenderHistogram(counts map[rune]int, width int) string {
+	if width < 1 {
+		width = 1
+	}
+
+	var chars []rune
+	maxCount := 0
+	for char, count := range counts {
+		if count > 0 {
+			chars = append(chars, char)
+			if count > maxCount {
+				maxCount = count
+			}
+		}
+	}
+	sort.Slice(chars, func(i, j int) bool {
+		if counts[chars[i]] != counts[chars[j]] {
+			return counts[chars[i]] > counts[chars[j]]
+		}
+		return chars[i] < chars[j]
+	})
+
+	labels := make([]string, len(chars))
+	labelWidth := 0
+	for i, char := range chars {
+		if unicode.IsGraphic(char) && !unicode.IsSpace(char) {
+			labels[i] = string(char)
+		} else {
+			labels[i] = fmt.Sprintf("U+%04X", char)
+		}
+		if n := utf8.RuneCountInString(labels[i]); n > labelWidth {
+			labelWidth = n
+		}
+	}
+
+	var chart strings.Builder
+	for i, char := range chars {
+		bar := counts[char] * width / maxCount
+		if bar == 0 {
+			bar = 1
+		}
+		chart.WriteString(labels[i])
+		chart.WriteString(strings.Repeat(" ", labelWidth-utf8.RuneCountInString(labels[i])+1))
+		chart.WriteString(strings.Repeat("#", bar))
+		fmt.Fprintf(&chart, " %d\n", counts[char])
+	}
+	return chart.String()
+}
@@ -0,0 +1,199 @@
+package voynich
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestIndexOfCoincidence(t *testing.T) {
+	// Every letter equally common: close to 1/26 for a long text
+	uniform := strings.Repeat("abcdefghijklmnopqrstuvwxyz", 1000)
+	if got := IndexOfCoincidence(uniform); math.Abs(got-1.0/26) > 1e-3 {
+		t.Errorf("IndexOfCoincidence(uniform) = %v, want about %v", got, 1.0/26)
+	}
+	if got := IndexOfCoincidence(strings.Repeat("e", 50)); got != 1 {
+		t.Errorf("IndexOfCoincidence of one repeated character = %v, want 1", got)
+	}
+	// Two pairs of equal characters among 4*3 ordered draws
+	if got := IndexOfCoincidence("aabb"); math.Abs(got-4.0/12) > 1e-12 {
+		t.Errorf("IndexOfCoincidence(\"aabb\") = %v, want 1/3", got)
+	}
+	for _, data := range []string{"", "a"} {
+		if got := IndexOfCoincidence(data); got != 0 {
+			t.Errorf("IndexOfCoincidence(%q) = %v, want 0", data, got)
+		}
+	}
+}
+
+func TestChiSquared(t *testing.T) {
+	expected := map[rune]float64{'a': 0.5, 'b': 0.25, 'c': 0.25}
+
+	statistic, df := ChiSquared("aabc", expected)
+	if statistic > 1e-12 || df != 2 {
+		t.Errorf("ChiSquared of a perfect match = %v with %d degrees of freedom, want 0 with 2", statistic, df)
+	}
+
+	// Percentages are renormalized like probabilities
+	percent := map[rune]float64{'a': 50, 'b': 25, 'c': 25}
+	if got, _ := ChiSquared("aabc", percent); got > 1e-12 {
+		t.Errorf("ChiSquared with percentages = %v, want 0", got)
+	}
+
+	// 'z' is not expected, so it takes the floor and adds a category. A
+	// floor of 1 against probabilities summing to 1 gives expected counts
+	// of 1.25, 0.625, 0.625 and 2.5 for a, b, c and z in five draws
+	statistic, df = ChiSquaredFloor("aabcz", expected, 1)
+	want := 0.75*0.75/1.25 + 2*0.375*0.375/0.625 + 1.5*1.5/2.5
+	if math.Abs(statistic-want) > 1e-12 || df != 3 {
+		t.Errorf("ChiSquaredFloor = %v with %d degrees of freedom, want %v with 3", statistic, df, want)
+	}
+	// The default floor makes an unexpected character dominate
+	if got, _ := ChiSquared("aabcz", expected); got < 1000 {
+		t.Errorf("ChiSquared with an unexpected character = %v, want a large value", got)
+	}
+
+	if got, df := ChiSquared("", expected); got != 0 || df != 0 {
+		t.Errorf("ChiSquared(\"\") = %v, %d, want 0, 0", got, df)
+	}
+}
+
+func TestBigramMatrix(t *testing.T) {
+	data := "aabab€a"
+	matrix := BigramMatrix(data)
+
+	total := 0
+	for _, row := range matrix {
+		for _, count := range row {
+			total += count
+		}
+	}
+	if want := utf8.RuneCountInString(data) - 1; total != want {
+		t.Errorf("bigram counts sum to %d, want %d", total, want)
+	}
+	// The diagonal "aa" is kept like any other transition
+	if matrix['a']['a'] != 1 || matrix['a']['b'] != 2 || matrix['b']['a'] != 1 || matrix['€']['a'] != 1 {
+		t.Errorf("unexpected matrix %v", matrix)
+	}
+	if got := BigramMatrix("a"); len(got) != 0 {
+		t.Errorf("BigramMatrix(\"a\") = %v, want no transitions", got)
+	}
+
+	var csv strings.Builder
+	if err := WriteBigramCSV(&csv, BigramMatrix("abba")); err != nil {
+		t.Fatal(err)
+	}
+	want := ",a,b\n" +
+		"a,0,1\n" +
+		"b,1,1\n"
+	if csv.String() != want {
+		t.Errorf("WriteBigramCSV wrote\n%s\nwant\n%s", csv.String(), want)
+	}
+}
+
+func TestAlphabetSizeAndDistinctRunes(t *testing.T) {
+	data := "daiin.chol€daiin"
+	if got := AlphabetSize(data); got != 10 {
+		t.Errorf("AlphabetSize = %d, want 10", got)
+	}
+	// In code point order, with the multi-byte '€' last
+	want := []rune{'.', 'a', 'c', 'd', 'h', 'i', 'l', 'n', 'o', '€'}
+	if got := DistinctRunes(data); !reflect.DeepEqual(got, want) {
+		t.Errorf("DistinctRunes = %q, want %q", got, want)
+	}
+
+	if got := AlphabetSize(""); got != 0 {
+		t.Errorf("AlphabetSize(\"\") = %d, want 0", got)
+	}
+	if got := DistinctRunes(""); len(got) != 0 {
+		t.Errorf("DistinctRunes(\"\") = %q, want none", got)
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	// Entropies 0, 1 and 2 bits: mean 1, population variance 2/3
+	mean, stddev := AggregateEntropy([]string{"aaaa", "ab", "abcd"})
+	if math.Abs(mean-1) > 1e-12 || math.Abs(stddev-math.Sqrt(2.0/3)) > 1e-12 {
+		t.Errorf("AggregateEntropy = %v, %v, want 1, %v", mean, stddev, math.Sqrt(2.0/3))
+	}
+
+	// Coincidence indices 1 and 0
+	mean, stddev = AggregateIndexOfCoincidence([]string{"aa", "ab"})
+	if math.Abs(mean-0.5) > 1e-12 || math.Abs(stddev-0.5) > 1e-12 {
+		t.Errorf("AggregateIndexOfCoincidence = %v, %v, want 0.5, 0.5", mean, stddev)
+	}
+
+	// Identical texts have no spread
+	texts := []string{phaseText, phaseText, phaseText}
+	mean, stddev = AggregateConditionalEntropy(texts)
+	if want := ConditionalEntropy(phaseText); math.Abs(mean-want) > 1e-12 || stddev > 1e-12 {
+		t.Errorf("AggregateConditionalEntropy = %v, %v, want %v, 0", mean, stddev, want)
+	}
+
+	if mean, stddev := AggregateEntropy(nil); mean != 0 || stddev != 0 {
+		t.Errorf("AggregateEntropy(nil) = %v, %v, want 0, 0", mean, stddev)
+	}
+}
+
+func TestRenderHistogram(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts map[rune]int
+		width  int
+		want   string
+	}{
+		{
+			// Ties go in code point order, so the space comes before 'b'
+			name:   "scaled",
+			counts: map[rune]int{'a': 10, 'b': 5, ' ': 5, 'c': 1},
+			width:  20,
+			want: "a      " + strings.Repeat("#", 20) + " 10\n" +
+				"U+0020 " + strings.Repeat("#", 10) + " 5\n" +
+				"b      " + strings.Repeat("#", 10) + " 5\n" +
+				"c      ## 1\n",
+		},
+		{
+			name:   "minimum bar",
+			counts: map[rune]int{'a': 100, 'b': 1, 'z': 0},
+			width:  10,
+			want:   "a " + strings.Repeat("#", 10) + " 100\nb # 1\n",
+		},
+		{
+			name:   "width below 1",
+			counts: map[rune]int{'a': 4, 'b': 2},
+			width:  0,
+			want:   "a # 4\nb # 2\n",
+		},
+		{name: "empty", counts: nil, width: 10, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderHistogram(tt.counts, tt.width); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{phaseText, phaseText, 1},
+		{"aabbc", "cbaba", 1}, // A shuffle has the same frequencies
+		{"abc", "aabbcc", 1},  // Proportional frequencies
+		{"ab", "bc", 0.5},     // One of two characters shared
+		{"aaaa", "bbbb", 0},   // Nothing in common
+		{"", "abc", 0},        // An empty text has no direction
+		{"abc", "", 0},
+		{"", "", 0},
+	}
+	for _, tt := range tests {
+		if got := CosineSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("CosineSimilarity(%.20q, %.20q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
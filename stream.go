@@ -0,0 +1,121 @@
+package voynich
+
+import "errors"
+
+// StreamDecoder decodes an LZ77 bitstream incrementally, for input that is
+// too long to hold in memory or arrives through a pipe. Bits are supplied
+// with Write or Feed in chunks of any size; the sliding window persists
+// across chunks and a command split across a chunk boundary is buffered
+// until the rest of it arrives. Decoded output is drained with Read.
+//
+// Feeding a stream in one chunk or in many produces the same output as
+// DecodeLZ77WithOptions on the whole stream.
+type StreamDecoder struct {
+	decoder *lz77Decoder
+	pending string // Bits of a command that is not yet complete
+	closed  bool
+	err     error // First decode error; the stream cannot continue past it
+}
+
+// NewStreamDecoder returns a StreamDecoder for the given field widths.
+func NewStreamDecoder(offsetBits, lengthBits int) (*StreamDecoder, error) {
+	return NewStreamDecoderWithOptions(offsetBits, lengthBits, DecodeOptions{})
+}
+
+// NewStreamDecoderWithOptions is NewStreamDecoder with additional control
+// over how the bitstream is interpreted.
+func NewStreamDecoderWithOptions(offsetBits, lengthBits int, opts DecodeOptions) (*StreamDecoder, error) {
+	decoder, err := newLZ77Decoder(offsetBits, lengthBits, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &StreamDecoder{decoder: decoder}, nil
+}
+
+// Feed decodes a chunk of '0'/'1' characters. Whitespace is ignored, as in
+// ParseBitStream; any other character is an error whose position is
+// relative to the chunk. A decode error, such as an invalid reference in
+// strict mode, stops the stream and is returned by every later call.
+func (s *StreamDecoder) Feed(bits string) error {
+	if s.closed {
+		return errors.New("feed on closed stream decoder")
+	}
+	if s.err != nil {
+		return s.err
+	}
+
+	if s.decoder.stats.StoppedAtEOS {
+		return nil // Anything after the end-of-stream marker is ignored
+	}
+
+	chunk, err := ParseBitStream(bits)
+	if err != nil {
+		return err
+	}
+	s.pending += chunk
+	return s.decodePending(false)
+}
+
+// decodePending decodes every complete command in the pending bits; an
+// incomplete one waits for more bits. A possible start of the end-of-stream
+// marker also waits unless final is set, when no more bits will come.
+func (s *StreamDecoder) decodePending(final bool) error {
+	position := 0
+	for position < len(s.pending) {
+		stop, wait := s.decoder.endOfStream(s.pending, position, final)
+		if stop {
+			s.pending = ""
+			return nil
+		}
+		if wait {
+			break
+		}
+
+		next, err := s.decoder.step(s.pending, position)
+		if err != nil {
+			var incomplete *incompleteError
+			if errors.As(err, &incomplete) && !final {
+				break
+			}
+			s.err = err
+			return err
+		}
+		position = next
+	}
+
+	s.decoder.base += position
+	s.pending = s.pending[position:]
+	return nil
+}
+
+// Write implements io.Writer by feeding p as a chunk of bitstream text.
+func (s *StreamDecoder) Write(p []byte) (int, error) {
+	if err := s.Feed(string(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Read implements io.Reader over the decoded output. Like bytes.Buffer it
+// returns io.EOF whenever no decoded output is buffered; more output may
+// become available after further writes.
+func (s *StreamDecoder) Read(p []byte) (int, error) {
+	return s.decoder.output.Read(p)
+}
+
+// Close marks the end of the bitstream. It returns the same truncation
+// error DecodeLZ77 would report if the stream ends partway through a
+// command. Buffered output can still be read after Close.
+func (s *StreamDecoder) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	if s.err != nil {
+		return s.err
+	}
+	// Decode what was held back for the marker and report a command cut
+	// off by the end of the stream
+	return s.decodePending(true)
+}
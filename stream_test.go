@@ -0,0 +1,96 @@
+package voynich
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// streamDecode feeds bitStream to a StreamDecoder in chunks of size bits,
+// closes it and returns everything it decoded with the first error.
+func streamDecode(t *testing.T, bitStream string, size int, opts DecodeOptions) (string, error) {
+	t.Helper()
+	decoder, err := NewStreamDecoderWithOptions(10, 4, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var output strings.Builder
+	var firstErr error
+	for start := 0; start < len(bitStream); start += size {
+		end := start + size
+		if end > len(bitStream) {
+			end = len(bitStream)
+		}
+		if _, err := decoder.Write([]byte(bitStream[start:end])); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if err := decoder.Close(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	if _, err := io.Copy(&output, decoder); err != nil {
+		t.Fatal(err)
+	}
+	return output.String(), firstErr
+}
+
+func TestStreamDecoderChunking(t *testing.T) {
+	bitStream, err := EncodeLZ77(benchText(2<<10), 10, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := DecodeLZ77(bitStream, 10, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A chunk of 1 or 7 bits splits nearly every command across chunks
+	for _, size := range []int{1, 7, 9, 15, 64, len(bitStream)} {
+		got, err := streamDecode(t, bitStream, size, DecodeOptions{})
+		if err != nil {
+			t.Fatalf("chunks of %d bits: %v", size, err)
+		}
+		if got != want {
+			t.Errorf("chunks of %d bits decoded %.40q..., want %.40q...", size, got, want)
+		}
+	}
+}
+
+func TestStreamDecoderSplitCommand(t *testing.T) {
+	decoder, err := NewStreamDecoder(10, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reference := referenceBits(2, 4)
+	for _, chunk := range []string{literalBits('a') + literalBits('b')[:4], literalBits('b')[4:] + reference[:6], reference[6:]} {
+		if err := decoder.Feed(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := decoder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(decoder)
+	if string(got) != "ababab" {
+		t.Errorf("decoded %q, want %q", got, "ababab")
+	}
+}
+
+func TestStreamDecoderTruncated(t *testing.T) {
+	bitStream := literalBits('a') + "1000"
+	_, wantErr := DecodeLZ77(bitStream, 10, 4)
+	got, err := streamDecode(t, bitStream, 3, DecodeOptions{})
+	if err == nil || err.Error() != wantErr.Error() {
+		t.Errorf("error = %v, want %v", err, wantErr)
+	}
+	if got != "a" {
+		t.Errorf("decoded %q, want %q", got, "a")
+	}
+
+	decoder, _ := NewStreamDecoder(10, 4)
+	decoder.Close()
+	if err := decoder.Feed("0"); err == nil {
+		t.Error("Feed after Close succeeded")
+	}
+}
@@ -0,0 +1,242 @@
+package voynich
+
+import (
+	"runtime"
+	"sync"
+)
+
+// SweepResult records the outcome of decoding a bitstream under one
+// combination of LZ77 parameters.
+type SweepResult struct {
+	OffsetBits int
+	LengthBits int
+	BitOrder   BitOrder
+	Reversed   bool // Set by callers that swept ReverseBits of the stream
+	DecodeResult
+	Entropy float64
+	Score   float64 // Set by RankSweep; lower is better
+	Err     error
+}
+
+// SweepLZ77 decodes a bitstream under every combination of offset and
+// length field widths and returns one result per combination, ordered by
+// offset width and then length width.
+func SweepLZ77(bitStream string, offsetOpts, lengthOpts []int) []SweepResult {
+	return SweepLZ77WithOptions(bitStream, offsetOpts, lengthOpts, DecodeOptions{})
+}
+
+// SweepLZ77WithOptions is SweepLZ77 using the given decode options for every
+// combination. Combinations are decoded concurrently by a pool of
+// runtime.NumCPU() workers; each result is stored at its combination's
+// index, so the ordering is the same as a serial sweep. The workers share
+// opts, so its Accept and Trace callbacks must be safe for concurrent use.
+func SweepLZ77WithOptions(bitStream string, offsetOpts, lengthOpts []int, opts DecodeOptions) []SweepResult {
+	return SweepLZ77WithProgress(bitStream, offsetOpts, lengthOpts, opts, nil)
+}
+
+// SweepLZ77WithProgress is SweepLZ77WithOptions that calls progress, when
+// it is not nil, with each result as its combination finishes, so long
+// sweeps can report how far they have got. Results arrive in completion
+// order rather than sweep order. Calls are serialized, so progress need
+// not be safe for concurrent use.
+func SweepLZ77WithProgress(bitStream string, offsetOpts, lengthOpts []int, opts DecodeOptions, progress func(SweepResult)) []SweepResult {
+	results := make([]SweepResult, len(offsetOpts)*len(lengthOpts))
+	if len(results) == 0 {
+		return results
+	}
+
+	// Each job is the index of one (offsetBits, lengthBits) combination
+	jobs := make(chan int)
+	workers := runtime.NumCPU()
+	if workers > len(results) {
+		workers = len(results)
+	}
+
+	var wg sync.WaitGroup
+	var progressMu sync.Mutex
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				offsetBits := offsetOpts[i/len(lengthOpts)]
+				lengthBits := lengthOpts[i%len(lengthOpts)]
+				decoded, err := DecodeLZ77WithOptions(bitStream, offsetBits, lengthBits, opts)
+
+				// Workers write disjoint indices, so no locking is needed
+				results[i] = SweepResult{
+					OffsetBits:   offsetBits,
+					LengthBits:   lengthBits,
+					BitOrder:     opts.BitOrder,
+					DecodeResult: decoded,
+					Entropy:      ShannonEntropy(decoded.Output),
+					Err:          err,
+				}
+
+				if progress != nil {
+					progressMu.Lock()
+					progress(results[i])
+					progressMu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := range results {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// DecodeWithPhaseSearch decodes the bitstream starting at each bit offset
+// from 0 to maxPhase and returns the best decode together with the phase
+// that produced it, to recover from leftover header bits or a bit-level
+// misalignment. A misaligned decode usually fails at the end of the stream
+// or is riddled with filtered literals and skipped references, so
+// candidates are compared in that order: decodes without error first, then
+// those with the fewest filtered literals plus skipped references, then
+// non-empty outputs, and finally the lowest Shannon entropy. Remaining ties
+// go to the smaller phase. A negative maxPhase is treated as 0.
+func DecodeWithPhaseSearch(bitStream string, offsetBits, lengthBits, maxPhase int) (best DecodeResult, phase int) {
+	phase, best = BestAlignment(bitStream, DecoderConfig{OffsetBits: offsetBits, LengthBits: lengthBits}, maxPhase)
+	return best, phase
+}
+
+// BestAlignment is DecodeWithPhaseSearch for a complete DecoderConfig: it
+// decodes the stream with the config at each phase from 0 to maxPhase bits
+// after cfg.HeaderBits and returns the phase of the best decode together
+// with that decode, judged the same way. Output entropy decides between
+// the decodes that are equally clean. Phases that would pass the end of
+// the stream are not tried, and a negative maxPhase is treated as 0.
+//
+// When nothing can be decoded, because cfg is invalid or its header is
+// longer than the stream, BestAlignment returns phase 0 with a zero
+// DecodeResult; DecodeWithConfig reports the reason.
+func BestAlignment(bitStream string, cfg DecoderConfig, maxPhase int) (phase int, result DecodeResult) {
+	if _, _, err := cfg.newDecoder(bitStream); err != nil {
+		return 0, DecodeResult{}
+	}
+	if maxPhase < 0 {
+		maxPhase = 0
+	}
+
+	header := cfg.HeaderBits
+	var bestQuality decodeQuality
+	for p := 0; p <= maxPhase && header+p <= len(bitStream); p++ {
+		cfg.HeaderBits = header + p
+		decoded, err := DecodeWithConfig(bitStream, cfg)
+		quality := qualityOf(decoded, err)
+		if p == 0 || quality.better(bestQuality) {
+			result, phase, bestQuality = decoded, p, quality
+		}
+	}
+	return phase, result
+}
+
+// decodeQuality summarizes a decode for comparing candidate parameters.
+type decodeQuality struct {
+	failed  bool
+	invalid int // Filtered literals plus skipped references
+	empty   bool
+	entropy float64
+}
+
+// qualityOf summarizes the result and error of one decode.
+func qualityOf(result DecodeResult, err error) decodeQuality {
+	return decodeQuality{
+		failed:  err != nil,
+		invalid: result.FilteredLiterals + result.SkippedRefs,
+		empty:   result.Output == "",
+		entropy: ShannonEntropy(result.Output),
+	}
+}
+
+// better reports whether q is strictly better than other: it decoded
+// without error, then has fewer invalid commands, then has output, then has
+// lower entropy.
+func (q decodeQuality) better(other decodeQuality) bool {
+	switch {
+	case q.failed != other.failed:
+		return !q.failed
+	case q.invalid != other.invalid:
+		return q.invalid < other.invalid
+	case q.empty != other.empty:
+		return !q.empty
+	default:
+		return q.entropy < other.entropy
+	}
+}
+
+// Params is a pair of LZ77 field widths.
+type Params struct {
+	OffsetBits int
+	LengthBits int
+}
+
+// RefineParameters hill-climbs from start towards better field widths
+// instead of sweeping a full grid. At each step it decodes the four
+// neighbours that change one width by one bit and moves to the best of
+// them if it beats the current parameters, judging decodes as
+// DecodeWithPhaseSearch does; it stops at a local optimum and returns it
+// with its decode. Only strict improvements are taken, so the climb cannot
+// cycle, and each parameter pair is decoded at most once. Widths in start
+// are clamped to the valid range of 1 to 30 bits.
+//
+// Widths with the same total as the true ones read every command at the
+// right position but split its fields differently, and often decode
+// cleanly to repetitive, low-entropy garbage that the climb prefers; check
+// the result against neighbouring totals.
+func RefineParameters(bitStream string, start Params) (Params, DecodeResult) {
+	type candidate struct {
+		result  DecodeResult
+		quality decodeQuality
+	}
+	cache := make(map[Params]candidate)
+	evaluate := func(p Params) candidate {
+		if c, ok := cache[p]; ok {
+			return c
+		}
+		decoded, err := DecodeLZ77WithOptions(bitStream, p.OffsetBits, p.LengthBits, DecodeOptions{})
+		c := candidate{decoded, qualityOf(decoded, err)}
+		cache[p] = c
+		return c
+	}
+
+	current := Params{clampWidth(start.OffsetBits), clampWidth(start.LengthBits)}
+	best := evaluate(current)
+	for {
+		next, nextBest := current, best
+		neighbours := []Params{
+			{current.OffsetBits - 1, current.LengthBits},
+			{current.OffsetBits + 1, current.LengthBits},
+			{current.OffsetBits, current.LengthBits - 1},
+			{current.OffsetBits, current.LengthBits + 1},
+		}
+		for _, p := range neighbours {
+			if p.OffsetBits < 1 || p.OffsetBits > maxFieldBits || p.LengthBits < 1 || p.LengthBits > maxFieldBits {
+				continue
+			}
+			if c := evaluate(p); c.quality.better(nextBest.quality) {
+				next, nextBest = p, c
+			}
+		}
+		if next == current {
+			return current, best.result
+		}
+		current, best = next, nextBest
+	}
+}
+
+// clampWidth limits a field width to the range the decoder accepts.
+func clampWidth(width int) int {
+	if width < 1 {
+		return 1
+	}
+	if width > maxFieldBits {
+		return maxFieldBits
+	}
+	return width
+}
@@ -0,0 +1,190 @@
+package voynich
+
+import "testing"
+
+// phaseText is long enough that a misaligned decode is clearly worse.
+const phaseText = "the quick brown fox jumps over the lazy dog while the lazy dog sleeps"
+
+func TestDecodeWithPhaseSearch(t *testing.T) {
+	bitStream, err := EncodeLZ77(phaseText, 10, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	best, phase := DecodeWithPhaseSearch("101"+bitStream, 10, 4, 8)
+	if phase != 3 || best.Output != phaseText {
+		t.Errorf("phase %d decoded %q, want phase 3 decoding %q", phase, best.Output, phaseText)
+	}
+
+	// A negative maxPhase still decodes phase 0
+	best, phase = DecodeWithPhaseSearch(bitStream, 10, 4, -1)
+	if phase != 0 || best.Output != phaseText {
+		t.Errorf("maxPhase -1: phase %d decoded %q, want phase 0 decoding %q", phase, best.Output, phaseText)
+	}
+}
+
+func TestBestAlignment(t *testing.T) {
+	bitStream, err := EncodeLZ77(phaseText, 10, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := DecoderConfig{OffsetBits: 10, LengthBits: 4}
+
+	phase, result := BestAlignment("101"+bitStream, cfg, 8)
+	if phase != 3 || result.Output != phaseText {
+		t.Errorf("phase %d decoded %q, want phase 3 decoding %q", phase, result.Output, phaseText)
+	}
+
+	// The phase counts from the end of the header
+	cfg.HeaderBits = 16
+	phase, result = BestAlignment("1111000011110000"+"101"+bitStream, cfg, 8)
+	if phase != 3 || result.Output != phaseText {
+		t.Errorf("with header: phase %d decoded %q, want phase 3 decoding %q", phase, result.Output, phaseText)
+	}
+
+	phase, result = BestAlignment(bitStream, DecoderConfig{OffsetBits: 10, LengthBits: 4}, -1)
+	if phase != 0 || result.Output != phaseText {
+		t.Errorf("maxPhase -1: phase %d decoded %q, want phase 0 decoding %q", phase, result.Output, phaseText)
+	}
+}
+
+func TestBestAlignmentNothingDecodable(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  DecoderConfig
+	}{
+		{"invalid widths", DecoderConfig{OffsetBits: 0, LengthBits: 4}},
+		{"header past end", DecoderConfig{OffsetBits: 10, LengthBits: 4, HeaderBits: 1000}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			phase, result := BestAlignment("0011000010", tt.cfg, 4)
+			if phase != 0 || result != (DecodeResult{}) {
+				t.Errorf("got phase %d, %+v, want phase 0 and a zero result", phase, result)
+			}
+		})
+	}
+}
+
+func TestSweepLZ77MatchesSerialOrder(t *testing.T) {
+	bitStream, err := EncodeLZ77(benchText(4<<10), 10, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offsetOpts, lengthOpts := []int{8, 9, 10, 11, 12}, []int{3, 4, 5}
+
+	// Repeat the sweep so a scheduling-dependent order would show up
+	for run := 0; run < 5; run++ {
+		results := SweepLZ77(bitStream, offsetOpts, lengthOpts)
+		i := 0
+		for _, offsetBits := range offsetOpts {
+			for _, lengthBits := range lengthOpts {
+				got := results[i]
+				if got.OffsetBits != offsetBits || got.LengthBits != lengthBits {
+					t.Fatalf("run %d: result %d is (%d, %d), want (%d, %d)",
+						run, i, got.OffsetBits, got.LengthBits, offsetBits, lengthBits)
+				}
+				want, _ := DecodeLZ77(bitStream, offsetBits, lengthBits)
+				if got.Output != want {
+					t.Fatalf("run %d: result %d output differs from a serial decode", run, i)
+				}
+				i++
+			}
+		}
+	}
+}
+
+// sweepSerial is the single-goroutine sweep that SweepLZ77's worker pool
+// replaced, kept as the benchmark baseline.
+func sweepSerial(bitStream string, offsetOpts, lengthOpts []int) []SweepResult {
+	results := make([]SweepResult, 0, len(offsetOpts)*len(lengthOpts))
+	for _, offsetBits := range offsetOpts {
+		for _, lengthBits := range lengthOpts {
+			decoded, err := DecodeLZ77WithOptions(bitStream, offsetBits, lengthBits, DecodeOptions{})
+			results = append(results, SweepResult{
+				OffsetBits:   offsetBits,
+				LengthBits:   lengthBits,
+				DecodeResult: decoded,
+				Entropy:      ShannonEntropy(decoded.Output),
+				Err:          err,
+			})
+		}
+	}
+	return results
+}
+
+func BenchmarkSweepLZ77(b *testing.B) {
+	bitStream, err := EncodeLZ77(benchText(256<<10), 10, 4)
+	if err != nil {
+		b.Fatal(err)
+	}
+	offsetOpts, lengthOpts := []int{8, 9, 10, 11, 12}, []int{3, 4, 5}
+
+	b.Run("serial", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sweepSerial(bitStream, offsetOpts, lengthOpts)
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			SweepLZ77(bitStream, offsetOpts, lengthOpts)
+		}
+	})
+}
+
+func TestSweepLZ77Length(t *testing.T) {
+	bitStream, err := EncodeLZ77(phaseText, 10, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		offsetOpts, lengthOpts []int
+	}{
+		{[]int{10}, []int{4}},
+		{[]int{8, 9, 10, 11}, []int{3, 4, 5}},
+		{[]int{0, 10, 99}, []int{4, -1}}, // Invalid widths still get a result
+		{nil, []int{4}},
+		{[]int{10}, nil},
+	}
+	for _, tt := range tests {
+		results := SweepLZ77(bitStream, tt.offsetOpts, tt.lengthOpts)
+		if want := len(tt.offsetOpts) * len(tt.lengthOpts); len(results) != want {
+			t.Errorf("SweepLZ77(%v, %v) returned %d results, want %d", tt.offsetOpts, tt.lengthOpts, len(results), want)
+		}
+	}
+
+	results := SweepLZ77(bitStream, []int{0, 10}, []int{4})
+	if results[0].Err == nil || results[1].Err != nil || results[1].Output != phaseText {
+		t.Errorf("got errors %v and %v, want only the invalid width to fail", results[0].Err, results[1].Err)
+	}
+}
+
+func TestRefineParameters(t *testing.T) {
+	text := benchText(2 << 10)
+	bitStream, err := EncodeLZ77(text, 10, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Params{OffsetBits: 10, LengthBits: 4}
+	for _, start := range []Params{{10, 4}, {11, 4}, {10, 3}} {
+		got, result := RefineParameters(bitStream, start)
+		if got != want || result.Output != text {
+			t.Errorf("from %+v: reached %+v decoding %.20q..., want %+v", start, got, result.Output, want)
+		}
+	}
+
+	// Moving one bit from the offset to the length field keeps every
+	// command aligned; halved offsets stay in the window, so the decode is
+	// clean and its repetitive garbage has the lower entropy
+	if got, _ := RefineParameters(bitStream, Params{10, 5}); got != (Params{9, 5}) {
+		t.Errorf("from {10 5}: reached %+v, want the aligned neighbour {9 5}", got)
+	}
+
+	// Out-of-range starting widths are clamped rather than rejected
+	if got, _ := RefineParameters(bitStream, Params{OffsetBits: 99, LengthBits: -3}); got.OffsetBits > 30 || got.LengthBits < 1 {
+		t.Errorf("clamped start reached %+v, want widths between 1 and 30", got)
+	}
+}
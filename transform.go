@@ -0,0 +1,238 @@
+package voynich
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Transpose writes the characters of data row by row into a grid of cols
+// columns and reads them back column by column, the rearrangement of a
+// columnar transposition cipher. The final row may be short; no padding is
+// added, so the columns past its end are one character shorter and the
+// result is always a permutation of data. When cols is less than 1 or at
+// least the length of data, data is returned unchanged.
+func Transpose(data string, cols int) string {
+	symbols := []rune(data)
+	if cols < 1 || cols >= len(symbols) {
+		return data
+	}
+
+	transposed := make([]rune, 0, len(symbols))
+	for col := 0; col < cols; col++ {
+		for i := col; i < len(symbols); i += cols {
+			transposed = append(transposed, symbols[i])
+		}
+	}
+	return string(transposed)
+}
+
+// KeyedShift applies a Vigenère-style shift over alphabet: the i-th
+// character of data found in the alphabet moves key[i%len(key)] places
+// along it, wrapping around at the end. Characters outside the alphabet
+// pass through unchanged and do not advance the key. Negative shifts move
+// backwards, so negating every key entry undoes the shift. With an empty
+// key or alphabet, data is returned unchanged.
+func KeyedShift(data string, key []int, alphabet []rune) string {
+	if len(key) == 0 || len(alphabet) == 0 {
+		return data
+	}
+
+	index := make(map[rune]int, len(alphabet))
+	for i, char := range alphabet {
+		if _, ok := index[char]; !ok {
+			index[char] = i
+		}
+	}
+
+	shifted := []rune(data)
+	keyPos := 0
+	for i, char := range shifted {
+		code, ok := index[char]
+		if !ok {
+			continue
+		}
+		code = (code + key[keyPos%len(key)]) % len(alphabet)
+		if code < 0 {
+			code += len(alphabet)
+		}
+		shifted[i] = alphabet[code]
+		keyPos++
+	}
+	return string(shifted)
+}
+
+// BWT computes the Burrows-Wheeler transform of data: the last characters
+// of all its rotations in sorted order, together with the primary index,
+// the row of the sorted rotations that holds data itself. The transform
+// groups characters that share a context into runs, which is why it often
+// precedes run-length, move-to-front or entropy coding. Rotations are
+// compared by code point, and equal rotations keep their original order.
+// InverseBWT undoes the transform. Empty input returns "", 0.
+func BWT(data string) (string, int) {
+	symbols := []rune(data)
+	n := len(symbols)
+	if n == 0 {
+		return "", 0
+	}
+
+	// Sort rotation start positions without materializing the rotations
+	rotations := make([]int, n)
+	for i := range rotations {
+		rotations[i] = i
+	}
+	sort.SliceStable(rotations, func(a, b int) bool {
+		ra, rb := rotations[a], rotations[b]
+		for k := 0; k < n; k++ {
+			ca, cb := symbols[(ra+k)%n], symbols[(rb+k)%n]
+			if ca != cb {
+				return ca < cb
+			}
+		}
+		return false
+	})
+
+	last := make([]rune, n)
+	index := 0
+	for row, start := range rotations {
+		last[row] = symbols[(start+n-1)%n]
+		if start == 0 {
+			index = row
+		}
+	}
+	return string(last), index
+}
+
+// InverseBWT reconstructs the text whose Burrows-Wheeler transform is data
+// with the given primary index, as returned by BWT. An index outside the
+// transformed text returns "".
+func InverseBWT(data string, index int) string {
+	last := []rune(data)
+	n := len(last)
+	if index < 0 || index >= n {
+		return ""
+	}
+
+	// Stable-sorting the last column gives the first column; order[row] is
+	// the row whose last character is the first character of row, which is
+	// the row of the rotation starting one character later in the text.
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return last[order[a]] < last[order[b]] })
+
+	text := make([]rune, n)
+	row := index
+	for i := 0; i < n; i++ {
+		row = order[row]
+		text[i] = last[row]
+	}
+	return string(text)
+}
+
+// MoveToFront applies the move-to-front transform over alphabet: each
+// character is replaced by its current index in a list that starts in
+// alphabet order, and the character is then moved to the front of the
+// list. After BWT the output is dominated by small indices, which the
+// entropy functions then measure. A character not in the alphabet is
+// written as -1 and leaves the list unchanged. InverseMoveToFront undoes
+// the transform.
+func MoveToFront(data string, alphabet []rune) []int {
+	list := append([]rune(nil), alphabet...)
+	indices := make([]int, 0, len(data))
+	for _, char := range data {
+		index := -1
+		for i, symbol := range list {
+			if symbol == char {
+				index = i
+				break
+			}
+		}
+		indices = append(indices, index)
+		if index > 0 {
+			copy(list[1:index+1], list[:index])
+			list[0] = char
+		}
+	}
+	return indices
+}
+
+// InverseMoveToFront reconstructs the text that MoveToFront turned into
+// indices with the same alphabet. An index outside the alphabet, including
+// the -1 written for unknown characters, is an error; the text decoded so
+// far is returned with it.
+func InverseMoveToFront(indices []int, alphabet []rune) (string, error) {
+	list := append([]rune(nil), alphabet...)
+	text := make([]rune, 0, len(indices))
+	for position, index := range indices {
+		if index < 0 || index >= len(list) {
+			return string(text), fmt.Errorf("index %d at position %d is outside the %d-symbol alphabet",
+				index, position, len(list))
+		}
+		char := list[index]
+		text = append(text, char)
+		copy(list[1:index+1], list[:index])
+		list[0] = char
+	}
+	return string(text), nil
+}
+
+// DeltaEncode replaces each character by its distance along alphabet from
+// the previous character, modulo the alphabet size, for testing
+// differential-coding hypotheses; the first character is encoded as its
+// distance from alphabet[0], that is its index. An empty or repeating
+// alphabet, or a character outside it, is an error. DeltaDecode undoes the
+// encoding.
+func DeltaEncode(data string, alphabet []rune) ([]int, error) {
+	index, err := deltaIndex(alphabet)
+	if err != nil {
+		return nil, err
+	}
+
+	deltas := make([]int, 0, len(data))
+	previous := 0
+	for position, char := range data {
+		code, ok := index[char]
+		if !ok {
+			return nil, fmt.Errorf("character %q at position %d is not in the alphabet", char, position)
+		}
+		deltas = append(deltas, (code-previous+len(alphabet))%len(alphabet))
+		previous = code
+	}
+	return deltas, nil
+}
+
+// DeltaDecode reconstructs the text that DeltaEncode turned into deltas
+// with the same alphabet. Deltas are taken modulo the alphabet size, so
+// negative differences work too. An empty or repeating alphabet is an
+// error.
+func DeltaDecode(deltas []int, alphabet []rune) (string, error) {
+	if _, err := deltaIndex(alphabet); err != nil {
+		return "", err
+	}
+
+	text := make([]rune, len(deltas))
+	code := 0
+	for i, delta := range deltas {
+		code = ((code+delta)%len(alphabet) + len(alphabet)) % len(alphabet)
+		text[i] = alphabet[code]
+	}
+	return string(text), nil
+}
+
+// deltaIndex validates an alphabet for delta coding and maps each rune to
+// its index.
+func deltaIndex(alphabet []rune) (map[rune]int, error) {
+	if len(alphabet) == 0 {
+		return nil, errors.New("alphabet is empty")
+	}
+	index := make(map[rune]int, len(alphabet))
+	for i, char := range alphabet {
+		if _, ok := index[char]; ok {
+			return nil, fmt.Errorf("alphabet contains %q more than once", char)
+		}
+		index[char] = i
+	}
+	return index, nil
+}
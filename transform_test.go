@@ -0,0 +1,148 @@
+package voynich
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTranspose(t *testing.T) {
+	tests := []struct {
+		data string
+		cols int
+		want string
+	}{
+		// a b c
+		// d e f
+		// g h i
+		{data: "abcdefghi", cols: 3, want: "adgbehcfi"},
+		// a b c d
+		// e f g h
+		// i j
+		{data: "abcdefghij", cols: 4, want: "aeibfjcgdh"},
+		{data: "abcdef", cols: 1, want: "abcdef"},
+		{data: "abc", cols: 3, want: "abc"},
+		{data: "abc", cols: 0, want: "abc"},
+		{data: "", cols: 2, want: ""},
+		{data: "αβγδ", cols: 2, want: "αγβδ"},
+	}
+	for _, tt := range tests {
+		if got := Transpose(tt.data, tt.cols); got != tt.want {
+			t.Errorf("Transpose(%q, %d) = %q, want %q", tt.data, tt.cols, got, tt.want)
+		}
+	}
+}
+
+func TestKeyedShift(t *testing.T) {
+	alphabet := []rune("abcdefghijklmnopqrstuvwxyz")
+	key := []int{11, 4, 12, 14, 13} // "lemon"
+
+	// The classic Vigenère example; spaces pass through without using
+	// up the key
+	shifted := KeyedShift("attack at dawn", key, alphabet)
+	if shifted != "lxfopv ef rnhr" {
+		t.Errorf("KeyedShift = %q, want %q", shifted, "lxfopv ef rnhr")
+	}
+
+	inverse := make([]int, len(key))
+	for i, k := range key {
+		inverse[i] = -k
+	}
+	if got := KeyedShift(shifted, inverse, alphabet); got != "attack at dawn" {
+		t.Errorf("negated key gave %q, want %q", got, "attack at dawn")
+	}
+
+	// Shifts wrap in both directions, including by more than the alphabet
+	if got := KeyedShift("az", []int{27, -27}, alphabet); got != "by" {
+		t.Errorf("wrapping shift = %q, want %q", got, "by")
+	}
+	if got := KeyedShift("abc", nil, alphabet); got != "abc" {
+		t.Errorf("empty key = %q, want the input", got)
+	}
+}
+
+func TestBWT(t *testing.T) {
+	// Sorted rotations: abanan anaban ananab banana nabana nanaba
+	transformed, index := BWT("banana")
+	if transformed != "nnbaaa" || index != 3 {
+		t.Errorf("BWT(\"banana\") = %q, %d, want \"nnbaaa\", 3", transformed, index)
+	}
+
+	for _, data := range []string{"banana", "a", "abracadabra", "aaaa", "qokeedy.daiin.qokeedy", "αβαβγ", phaseText} {
+		transformed, index := BWT(data)
+		if got := InverseBWT(transformed, index); got != data {
+			t.Errorf("InverseBWT(BWT(%q)) = %q", data, got)
+		}
+	}
+
+	if transformed, index := BWT(""); transformed != "" || index != 0 {
+		t.Errorf("BWT(\"\") = %q, %d, want \"\", 0", transformed, index)
+	}
+	if got := InverseBWT("nnbaaa", 6); got != "" {
+		t.Errorf("InverseBWT with index past the end = %q, want \"\"", got)
+	}
+}
+
+func TestMoveToFront(t *testing.T) {
+	alphabet := []rune("abn")
+	indices := MoveToFront("banana", alphabet)
+	if want := []int{1, 1, 2, 1, 1, 1}; !reflect.DeepEqual(indices, want) {
+		t.Errorf("MoveToFront(\"banana\") = %v, want %v", indices, want)
+	}
+
+	letters := []rune(" abcdefghijklmnopqrstuvwxyz")
+	for _, data := range []string{"banana", "", "aaaa", phaseText} {
+		got, err := InverseMoveToFront(MoveToFront(data, letters), letters)
+		if err != nil || got != data {
+			t.Errorf("round trip of %q gave %q, %v", data, got, err)
+		}
+	}
+
+	// An unknown character is written as -1, which cannot be inverted
+	indices = MoveToFront("ab!a", alphabet)
+	if want := []int{0, 1, -1, 1}; !reflect.DeepEqual(indices, want) {
+		t.Errorf("MoveToFront(\"ab!a\") = %v, want %v", indices, want)
+	}
+	if got, err := InverseMoveToFront(indices, alphabet); err == nil || got != "ab" {
+		t.Errorf("InverseMoveToFront = %q, %v, want \"ab\" and an error", got, err)
+	}
+}
+
+func TestDelta(t *testing.T) {
+	alphabet := []rune("abc")
+	deltas, err := DeltaEncode("abca", alphabet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The step from 'c' back to 'a' wraps around to 1
+	if want := []int{0, 1, 1, 1}; !reflect.DeepEqual(deltas, want) {
+		t.Errorf("DeltaEncode(\"abca\") = %v, want %v", deltas, want)
+	}
+
+	letters := []rune(" abcdefghijklmnopqrstuvwxyz")
+	for _, data := range []string{"abca", "", "zzz", phaseText} {
+		deltas, err := DeltaEncode(data, letters)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := DeltaDecode(deltas, letters); err != nil || got != data {
+			t.Errorf("round trip of %q gave %q, %v", data, got, err)
+		}
+	}
+
+	// Negative deltas are taken modulo the alphabet size
+	if got, err := DeltaDecode([]int{2, -1, -4}, alphabet); err != nil || got != "cba" {
+		t.Errorf("DeltaDecode with negative deltas = %q, %v, want \"cba\"", got, err)
+	}
+
+	if _, err := DeltaEncode("abd", alphabet); err == nil {
+		t.Error("DeltaEncode of a character outside the alphabet succeeded, want an error")
+	}
+	for _, bad := range [][]rune{nil, []rune("aba")} {
+		if _, err := DeltaEncode("a", bad); err == nil {
+			t.Errorf("DeltaEncode with alphabet %q succeeded, want an error", bad)
+		}
+		if _, err := DeltaDecode([]int{0}, bad); err == nil {
+			t.Errorf("DeltaDecode with alphabet %q succeeded, want an error", bad)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package voynich
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WordOptions controls how text is split into words.
+type WordOptions struct {
+	// StripPunctuation trims leading and trailing punctuation from each
+	// word and drops words that consist only of punctuation.
+	StripPunctuation bool
+
+	// FoldCase lowercases every word, so "Daiin" and "daiin" count as the
+	// same word.
+	FoldCase bool
+}
+
+// Words splits text into words on runs of whitespace, so repeated spaces,
+// tabs, newlines and leading or trailing whitespace produce no empty words.
+func Words(data string, opts WordOptions) []string {
+	fields := strings.Fields(data)
+	if !opts.StripPunctuation && !opts.FoldCase {
+		return fields
+	}
+
+	words := fields[:0]
+	for _, word := range fields {
+		if opts.StripPunctuation {
+			word = strings.TrimFunc(word, unicode.IsPunct)
+		}
+		if opts.FoldCase {
+			word = strings.ToLower(word)
+		}
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// WordCounts returns the number of occurrences of each whitespace-separated
+// word in a string.
+func WordCounts(data string) map[string]int {
+	return WordCountsWithOptions(data, WordOptions{})
+}
+
+// WordCountsWithOptions is WordCounts with control over tokenization.
+func WordCountsWithOptions(data string, opts WordOptions) map[string]int {
+	counts := make(map[string]int)
+	for _, word := range Words(data, opts) {
+		counts[word]++
+	}
+	return counts
+}
+
+// WordEntropy computes the Shannon entropy in bits/word of the word
+// distribution of a string. Word-level statistics are often more telling
+// than character entropy when comparing the Voynich with known languages.
+func WordEntropy(data string) float64 {
+	return WordEntropyWithOptions(data, WordOptions{})
+}
+
+// WordEntropyWithOptions is WordEntropy with control over tokenization.
+func WordEntropyWithOptions(data string, opts WordOptions) float64 {
+	words := Words(data, opts)
+	counts := make(map[string]int)
+	for _, word := range words {
+		counts[word]++
+	}
+	return entropyOfCounts(counts, len(words))
+}
+
+// WordLengthHistogram returns the number of whitespace-separated words of
+// each length, in characters. Empty input returns an empty map.
+func WordLengthHistogram(data string) map[int]int {
+	histogram := make(map[int]int)
+	for _, word := range Words(data, WordOptions{}) {
+		histogram[utf8.RuneCountInString(word)]++
+	}
+	return histogram
+}
+
+// MeanWordLength returns the average length in characters of the
+// whitespace-separated words in a string, or 0 when there are none.
+func MeanWordLength(data string) float64 {
+	words := Words(data, WordOptions{})
+	if len(words) == 0 {
+		return 0
+	}
+
+	total := 0
+	for _, word := range words {
+		total += utf8.RuneCountInString(word)
+	}
+	return float64(total) / float64(len(words))
+}
+
+// ZipfFit ranks the words of a string by frequency and fits log(frequency)
+// against log(rank) by least squares, returning the slope and the
+// coefficient of determination R². Natural languages typically give a slope
+// near -1 with a high R². Words of equal frequency are ranked
+// alphabetically so the fit is deterministic. Fewer than two distinct
+// words, or frequencies that are all equal, return an R² of 0.
+func ZipfFit(data string) (slope, r2 float64) {
+	counts := WordCounts(data)
+	if len(counts) < 2 {
+		return 0, 0
+	}
+
+	words := make([]string, 0, len(counts))
+	for word := range counts {
+		words = append(words, word)
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if counts[words[i]] != counts[words[j]] {
+			return counts[words[i]] > counts[words[j]]
+		}
+		return words[i] < words[j]
+	})
+
+	// Least-squares fit of y = log(frequency) on x = log(rank)
+	n := float64(len(words))
+	var sumX, sumY, sumXX, sumXY float64
+	for i, word := range words {
+		x := math.Log(float64(i + 1))
+		y := math.Log(float64(counts[word]))
+		sumX += x
+		sumY += y
+		sumXX += x * x
+		sumXY += x * y
+	}
+	slope = (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+	intercept := (sumY - slope*sumX) / n
+
+	// R² = 1 - SS_res / SS_tot
+	meanY := sumY / n
+	var ssRes, ssTot float64
+	for i, word := range words {
+		x := math.Log(float64(i + 1))
+		y := math.Log(float64(counts[word]))
+		residual := y - (intercept + slope*x)
+		ssRes += residual * residual
+		ssTot += (y - meanY) * (y - meanY)
+	}
+	if ssTot == 0 {
+		return slope, 0
+	}
+	return slope, 1 - ssRes/ssTot
+}
+
+// DictionaryScore counts how many words of a decode appear in a word set,
+// returning the number of hits and their fraction of all words (0 when the
+// decode has no words). Punctuation is stripped from each word before the
+// lookup and case must match exactly. A decode full of dictionary words is
+// far stronger evidence than a low entropy alone.
+func DictionaryScore(data string, words map[string]bool) (hits int, ratio float64) {
+	return DictionaryScoreWithOptions(data, words, WordOptions{StripPunctuation: true})
+}
+
+// DictionaryScoreWithOptions is DictionaryScore with control over
+// tokenization. With FoldCase set the decode is lowercased before the
+// lookup, so the word set should hold lowercase words.
+func DictionaryScoreWithOptions(data string, words map[string]bool, opts WordOptions) (hits int, ratio float64) {
+	tokens := Words(data, opts)
+	if len(tokens) == 0 {
+		return 0, 0
+	}
+
+	for _, token := range tokens {
+		if words[token] {
+			hits++
+		}
+	}
+	return hits, float64(hits) / float64(len(tokens))
+}
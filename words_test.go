@@ -0,0 +1,134 @@
+package voynich
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		opts WordOptions
+		want []string
+	}{
+		{name: "empty", data: "", want: []string{}},
+		{name: "only whitespace", data: "  \t ", want: []string{}},
+		{name: "multiple spaces", data: "daiin   daiin  chol", want: []string{"daiin", "daiin", "chol"}},
+		{name: "leading and trailing", data: "  qokeedy chol ", want: []string{"qokeedy", "chol"}},
+		{name: "punctuation kept", data: "Hello, world!", want: []string{"Hello,", "world!"}},
+		{
+			name: "punctuation stripped",
+			data: "Hello, world! -- (again)",
+			opts: WordOptions{StripPunctuation: true},
+			want: []string{"Hello", "world", "again"},
+		},
+		{
+			name: "case folded",
+			data: "Daiin daiin DAIIN",
+			opts: WordOptions{FoldCase: true},
+			want: []string{"daiin", "daiin", "daiin"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Words(tt.data, tt.opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Words(%q) = %q, want %q", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWordCountsAndEntropy(t *testing.T) {
+	data := "  daiin daiin   chol shol \n"
+	want := map[string]int{"daiin": 2, "chol": 1, "shol": 1}
+	if got := WordCounts(data); !maps.Equal(got, want) {
+		t.Errorf("WordCounts = %v, want %v", got, want)
+	}
+	// Probabilities 1/2, 1/4 and 1/4
+	if got := WordEntropy(data); math.Abs(got-1.5) > 1e-12 {
+		t.Errorf("WordEntropy = %v, want 1.5", got)
+	}
+	if got := WordEntropy("same same same"); got != 0 {
+		t.Errorf("WordEntropy of one repeated word = %v, want 0", got)
+	}
+	if got := WordEntropyWithOptions("end. end!", WordOptions{StripPunctuation: true}); got != 0 {
+		t.Errorf("WordEntropy with punctuation stripped = %v, want 0", got)
+	}
+}
+
+func TestWordLengthHistogram(t *testing.T) {
+	data := "ol\tdaiin\nchol  qokeedy\r\nol"
+	want := map[int]int{2: 2, 4: 1, 5: 1, 7: 1}
+	if got := WordLengthHistogram(data); !maps.Equal(got, want) {
+		t.Errorf("WordLengthHistogram = %v, want %v", got, want)
+	}
+	if got, want := MeanWordLength(data), 20.0/5; got != want {
+		t.Errorf("MeanWordLength = %v, want %v", got, want)
+	}
+	// Lengths are in characters, not bytes
+	if got := WordLengthHistogram("née"); !maps.Equal(got, map[int]int{3: 1}) {
+		t.Errorf("WordLengthHistogram(\"née\") = %v, want one word of 3", got)
+	}
+
+	if got := WordLengthHistogram(""); got == nil || len(got) != 0 {
+		t.Errorf("WordLengthHistogram(\"\") = %#v, want an empty map", got)
+	}
+	if got := MeanWordLength(" \n\t"); got != 0 {
+		t.Errorf("MeanWordLength of whitespace = %v, want 0", got)
+	}
+}
+
+func TestZipfFit(t *testing.T) {
+	// Word r occurs 1200/r times, an exact Zipf distribution up to rounding
+	var data strings.Builder
+	for rank := 1; rank <= 20; rank++ {
+		word := fmt.Sprintf("w%02d ", rank)
+		data.WriteString(strings.Repeat(word, 1200/rank))
+	}
+	slope, r2 := ZipfFit(data.String())
+	if math.Abs(slope+1) > 0.01 || r2 < 0.999 {
+		t.Errorf("ZipfFit of Zipfian words = slope %v, R² %v, want about -1 and 1", slope, r2)
+	}
+
+	// Frequencies falling as 1/r² give twice the slope
+	data.Reset()
+	for rank := 1; rank <= 10; rank++ {
+		data.WriteString(strings.Repeat(fmt.Sprintf("w%02d ", rank), 10000/(rank*rank)))
+	}
+	if slope, _ := ZipfFit(data.String()); math.Abs(slope+2) > 0.01 {
+		t.Errorf("ZipfFit of 1/r² words = slope %v, want about -2", slope)
+	}
+
+	if _, r2 := ZipfFit("a b c d a b c d"); r2 != 0 {
+		t.Errorf("ZipfFit of equal frequencies: R² = %v, want 0", r2)
+	}
+	if slope, r2 := ZipfFit("daiin daiin"); slope != 0 || r2 != 0 {
+		t.Errorf("ZipfFit of one distinct word = %v, %v, want 0, 0", slope, r2)
+	}
+}
+
+func TestDictionaryScore(t *testing.T) {
+	words := map[string]bool{"the": true, "herb": true, "root": true}
+	data := "The herb, the ROOT and the leaf."
+
+	// Punctuation is stripped but case must match exactly
+	hits, ratio := DictionaryScore(data, words)
+	if hits != 3 || ratio != 3.0/7 {
+		t.Errorf("DictionaryScore = %d, %v, want 3, 3/7", hits, ratio)
+	}
+
+	// Folding case also matches "The" and "ROOT"
+	hits, ratio = DictionaryScoreWithOptions(data, words, WordOptions{StripPunctuation: true, FoldCase: true})
+	if hits != 5 || ratio != 5.0/7 {
+		t.Errorf("DictionaryScore with FoldCase = %d, %v, want 5, 5/7", hits, ratio)
+	}
+
+	if hits, ratio := DictionaryScore(" ... ", words); hits != 0 || ratio != 0 {
+		t.Errorf("DictionaryScore without words = %d, %v, want 0, 0", hits, ratio)
+	}
+}